@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/appvia/wfclient/pkg/client"
+	"github.com/appvia/wfclient/pkg/client/config"
+	"github.com/spf13/cobra"
+)
+
+var authStatusProfile string
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Inspect client authentication",
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Describe the credentials of a profile",
+	Long: `Describe the credentials of the current profile, or the profile given with --profile, as JSON.
+This includes the kind, subject, scopes, audience and expiry of each token, with token values
+redacted.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return err
+		}
+
+		desc, err := client.DescribeCredentials(cfg, authStatusProfile)
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(desc)
+	},
+}
+
+func init() {
+	authStatusCmd.Flags().StringVar(&authStatusProfile, "profile", "", "profile to describe, defaulting to the current profile")
+
+	authCmd.AddCommand(authStatusCmd)
+	rootCmd.AddCommand(authCmd)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/appvia/wfclient/pkg/client/config"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the client configuration",
+}
+
+var configDoctorCmd = &cobra.Command{
+	Use:          "doctor",
+	Short:        "Check the client configuration for problems",
+	Long:         `Validate the client configuration, reporting any problems found with profiles, servers and users.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+
+		if config.IsEphemeralConfig() {
+			fmt.Fprintf(out, "Using configuration from the %s and %s environment variables\n", config.EnvWayfinderServer, config.EnvWayfinderToken)
+		} else {
+			for _, path := range cfg.Sources() {
+				fmt.Fprintf(out, "Using configuration file %s\n", path)
+			}
+		}
+
+		profiles := cfg.ListProfiles()
+		sort.Strings(profiles)
+		for _, name := range profiles {
+			origin := cfg.Origin(name)
+			current := ""
+			if name == cfg.GetCurrentProfile() {
+				current = " (current)"
+			}
+			fmt.Fprintf(out, "Profile %s%s: auth %s\n", name, current, cfg.GetProfileAuthMethod(name))
+			if origin.Profile != "" {
+				fmt.Fprintf(out, "  defined in %s, server from %s, user from %s\n", origin.Profile, originOrNone(origin.Server), originOrNone(origin.AuthInfo))
+			}
+		}
+
+		fmt.Fprintln(out, "Effective settings:")
+		for _, v := range cfg.GetEffectiveValues("") {
+			fmt.Fprintf(out, "  %s: %s (from %s)\n", v.Name, v.Value, v.Source)
+		}
+
+		verr := cfg.Validate()
+		if verr == nil {
+			fmt.Fprintln(out, "No problems found")
+			return nil
+		}
+
+		for _, fe := range verr.GetWarnings() {
+			fmt.Fprintf(out, "WARNING %s: %s\n", fe.Field, fe.Message)
+		}
+		problems := verr.GetNonWarnings()
+		for _, fe := range problems {
+			fmt.Fprintf(out, "ERROR %s: %s\n", fe.Field, fe.Message)
+		}
+		if len(problems) > 0 {
+			return errors.New(pluralise(len(problems), "problem") + " found in the client configuration")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configDoctorCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func originOrNone(path string) string {
+	if path == "" {
+		return "(none)"
+	}
+
+	return path
+}
+
+func pluralise(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "github.com/appvia/wfclient/pkg/apis/core/v1alpha1"
+	"github.com/appvia/wfclient/pkg/client"
+	"github.com/appvia/wfclient/pkg/client/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deleteWorkspace string
+	deleteVersion   string
+	deleteDryRun    bool
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete KIND NAME",
+	Short: "Delete an object along with the objects depending on it",
+	Long: `Delete an object along with every object which depends on it, removing the dependents
+first. With --dry-run nothing is deleted, and the objects which would be deleted are printed as a
+tree with each dependent indented below the object it depends on.`,
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		obj, err := client.NewObjectForKind(args[0])
+		if err != nil {
+			return err
+		}
+		obj.SetName(args[1])
+		obj.SetNamespace(corev1.WorkspaceKey(deleteWorkspace).Namespace())
+		if v, ok := obj.(corev1.Versioned); ok {
+			if deleteVersion == "" {
+				return fmt.Errorf("--version is required to delete a %s", args[0])
+			}
+			v.SetVersion(corev1.ObjectVersion(deleteVersion))
+		}
+
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return err
+		}
+		wfClient, err := newClient(cfg)
+		if err != nil {
+			return err
+		}
+		defer wfClient.Close()
+
+		tree, err := client.DeleteWithDependents(context.Background(), client.NewWFClientForClient(wfClient), obj,
+			client.WithDryRun(deleteDryRun),
+			client.WithDeletionStatusHandler(func(_ context.Context, obj client.Object, status corev1.CommonStatus) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "%s %s: %s\n", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName(), status.Status)
+			}),
+		)
+		if err != nil {
+			return err
+		}
+
+		if deleteDryRun {
+			fmt.Fprintln(cmd.OutOrStdout(), "The following objects would be deleted:")
+		} else {
+			fmt.Fprintln(cmd.OutOrStdout(), "Deleted:")
+		}
+		fmt.Fprint(cmd.OutOrStdout(), tree)
+
+		return nil
+	},
+}
+
+func init() {
+	deleteCmd.Flags().StringVar(&deleteWorkspace, "workspace", "", "workspace of the object, if it is workspaced")
+	deleteCmd.Flags().StringVar(&deleteVersion, "version", "", "version of the object, if it is versioned")
+	deleteCmd.Flags().BoolVar(&deleteDryRun, "dry-run", false, "print the objects which would be deleted without deleting them")
+
+	rootCmd.AddCommand(deleteCmd)
+}
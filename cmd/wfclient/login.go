@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/appvia/wfclient/pkg/client"
+	"github.com/appvia/wfclient/pkg/client/config"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// EnvWayfinderPassword is the environment variable holding the password of a local user
+const EnvWayfinderPassword = "WAYFINDER_PASSWORD"
+
+var (
+	loginUsername        string
+	loginAccessTokenFile string
+	loginSSO             bool
+	loginNoBrowser       bool
+)
+
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Login to the server of the current profile",
+	Long: `Login to the server of the current profile, either through single sign-on with --sso, as a
+local user with --username or with an access token read from --access-token-file. The password of
+a local user is read from the WAYFINDER_PASSWORD environment variable, or from stdin.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return err
+		}
+		wfClient, err := newClient(cfg)
+		if err != nil {
+			return err
+		}
+		defer wfClient.Close()
+
+		switch {
+		case loginSSO:
+			options := client.SSOLoginOptions{Out: cmd.ErrOrStderr()}
+			if !loginNoBrowser {
+				options.OpenBrowser = openBrowser
+			}
+			if err := wfClient.LoginSSO(cmd.Context(), options); err != nil {
+				return err
+			}
+
+		case loginAccessTokenFile != "":
+			token, err := os.ReadFile(loginAccessTokenFile)
+			if err != nil {
+				return err
+			}
+			if err := wfClient.LoginWithAccessToken(cmd.Context(), strings.TrimSpace(string(token))); err != nil {
+				return err
+			}
+
+		case loginUsername != "":
+			password := os.Getenv(EnvWayfinderPassword)
+			if password == "" {
+				if password, err = readPassword(cmd); err != nil {
+					return err
+				}
+			}
+			if err := wfClient.LoginLocal(cmd.Context(), loginUsername, password); err != nil {
+				return err
+			}
+
+		default:
+			return errors.New("one of --sso, --username or --access-token-file must be provided")
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Logged in to profile %s\n", wfClient.CurrentProfile())
+
+		return nil
+	},
+}
+
+// readPassword prompts for a password on stdin, without echoing it when stdin is a terminal
+func readPassword(cmd *cobra.Command) (string, error) {
+	fmt.Fprint(cmd.ErrOrStderr(), "Password: ")
+	if f, ok := cmd.InOrStdin().(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		password, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(cmd.ErrOrStderr())
+
+		return string(password), err
+	}
+
+	line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+var logoutCmd = &cobra.Command{
+	Use:          "logout",
+	Short:        "Logout of the current profile, revoking and clearing its credentials",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return err
+		}
+		wfClient, err := newClient(cfg)
+		if err != nil {
+			return err
+		}
+		defer wfClient.Close()
+
+		return wfClient.Logout(cmd.Context())
+	},
+}
+
+// openBrowser opens the URL in the default browser of the user
+func openBrowser(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
+
+func init() {
+	loginCmd.Flags().BoolVar(&loginSSO, "sso", false, "login through the single sign-on provider of the server")
+	loginCmd.Flags().BoolVar(&loginNoBrowser, "no-browser", false, "print the login URL rather than opening a browser")
+	loginCmd.Flags().StringVar(&loginUsername, "username", "", "username of a local user to login as")
+	loginCmd.Flags().StringVar(&loginAccessTokenFile, "access-token-file", "", "file holding an access token to login with")
+
+	rootCmd.AddCommand(loginCmd, logoutCmd)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/appvia/wfclient/pkg/client"
 	"github.com/appvia/wfclient/pkg/client/config"
@@ -11,6 +12,9 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
+// harFile is the file to record an HTTP archive of the requests made to
+var harFile string
+
 var rootCmd = &cobra.Command{
 	Use:   "wfclient",
 	Short: "Simple example of using the wfclient library in a CLI tool",
@@ -37,10 +41,14 @@ var serverInfoCmd = &cobra.Command{
 		}
 
 		// @step: we create an client from the configuration
-		wfClient := client.NewClient(cfg, client.UseUpdateHandler(updateClientConfiguration(cfg)))
+		wfClient, err := newClient(cfg,
+			client.UseTokenExpiryWarning(tokenExpiryWarning, func(w client.TokenExpiryWarning) {
+				fmt.Fprintf(os.Stderr, "WARNING: %s\n", w)
+			}))
 		if err != nil {
 			return err
 		}
+		defer wfClient.Close()
 		if profile != "" {
 			wfClient.OverrideProfile(profile)
 		}
@@ -69,7 +77,11 @@ var serverInfoCmd = &cobra.Command{
 	},
 }
 
+// tokenExpiryWarning is how long before expiry users are warned about stored tokens
+const tokenExpiryWarning = 14 * 24 * time.Hour
+
 func init() {
+	rootCmd.PersistentFlags().StringVar(&harFile, "har", "", "Record an HTTP archive of the requests made to the file, with credentials redacted")
 	rootCmd.AddCommand(serverInfoCmd)
 }
 
@@ -80,12 +92,54 @@ func main() {
 	}
 }
 
+// newClient creates a client for the configuration, persisting updates to it and recording an HTTP
+// archive when --har is set. The client must be closed for the archive to be finished.
+func newClient(cfg *config.Config, options ...client.OptionFunc) (client.SessionClient, error) {
+	options = append([]client.OptionFunc{client.UseUpdateHandler(updateClientConfiguration(cfg))}, options...)
+	if harFile == "" {
+		return client.NewSessionClient(cfg, options...)
+	}
+
+	file, err := os.Create(harFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP archive: %w", err)
+	}
+	wfClient, err := client.NewSessionClient(cfg, append(options, client.UseHARRecorder(file))...)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &archivedClient{SessionClient: wfClient, file: file}, nil
+}
+
+// archivedClient closes the HTTP archive file along with the client
+type archivedClient struct {
+	client.SessionClient
+	file *os.File
+}
+
+// Close finishes the archive and closes the file
+func (c *archivedClient) Close() error {
+	if err := c.SessionClient.Close(); err != nil {
+		c.file.Close()
+		return err
+	}
+
+	return c.file.Close()
+}
+
 func updateClientConfiguration(cfg *config.Config) client.UpdateHandlerFunc {
 	return func() error {
 		if config.IsEphemeralConfig() {
 			return nil
 		}
 
+		// write each entry back to the file it was loaded from
+		if len(cfg.Sources()) > 0 {
+			return cfg.Save()
+		}
+
 		return config.UpdateConfig(cfg, config.GetClientConfigurationPath())
 	}
 }
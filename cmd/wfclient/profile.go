@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/appvia/wfclient/pkg/client/config"
+	"github.com/spf13/cobra"
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage client profiles",
+}
+
+var profileCreateCmd = &cobra.Command{
+	Use:          "create NAME ENDPOINT",
+	Short:        "Create a profile for a Wayfinder server",
+	Long:         `Create a profile for a Wayfinder server, asking whether to trust its certificate if it is not trusted by the system.`,
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return err
+		}
+		if cfg.HasProfile(args[0]) {
+			return fmt.Errorf("profile %s already exists", args[0])
+		}
+
+		if err := cfg.CreateProfileWithTrust(args[0], args[1], promptTrust(cmd.InOrStdin(), cmd.OutOrStdout())); err != nil {
+			return err
+		}
+		cfg.CurrentProfile = args[0]
+
+		return updateClientConfiguration(cfg)()
+	},
+}
+
+var profileTrustCmd = &cobra.Command{
+	Use:          "trust NAME",
+	Short:        "Trust the certificate currently presented by the server of a profile",
+	Long:         `Trust the certificate currently presented by the server of a profile, such as after the server key has been changed.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return err
+		}
+		if !cfg.HasProfile(args[0]) {
+			return fmt.Errorf("profile %s does not exist", args[0])
+		}
+
+		if err := cfg.TrustServer(cfg.GetProfile(args[0]).Server, promptTrust(cmd.InOrStdin(), cmd.OutOrStdout())); err != nil {
+			return err
+		}
+
+		return updateClientConfiguration(cfg)()
+	},
+}
+
+var profileExportCmd = &cobra.Command{
+	Use:   "export NAME",
+	Short: "Export a profile as a portable bundle",
+	Long: `Export a profile as a portable bundle which can be imported by another user. Credentials are
+only included when requested, encrypted with the passphrase read from --passphrase-file.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return err
+		}
+
+		options := config.ExportOptions{IncludeCredentials: exportCredentials}
+		if exportCredentials {
+			if options.Passphrase, err = readPassphrase(passphraseFile); err != nil {
+				return err
+			}
+		}
+
+		doc, err := cfg.ExportProfile(args[0], options)
+		if err != nil {
+			return err
+		}
+		_, err = cmd.OutOrStdout().Write(doc)
+
+		return err
+	},
+}
+
+var profileImportCmd = &cobra.Command{
+	Use:   "import FILE",
+	Short: "Import a profile from a bundle",
+	Long: `Import a profile from a bundle created by profile export, or from stdin when FILE is -. Any
+credentials in the bundle are decrypted with the passphrase read from --passphrase-file. Bundles which
+skip TLS verification or use a proxy are rejected unless --allow-insecure is given.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var doc []byte
+		var err error
+		if args[0] == "-" {
+			doc, err = io.ReadAll(cmd.InOrStdin())
+		} else {
+			doc, err = os.ReadFile(args[0])
+		}
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return err
+		}
+
+		var options []config.ImportOption
+		if passphraseFile != "" {
+			passphrase, err := readPassphrase(passphraseFile)
+			if err != nil {
+				return err
+			}
+			options = append(options, config.WithPassphrase(passphrase))
+		}
+
+		if importInsecure {
+			options = append(options, config.WithInsecureSettings())
+		}
+
+		name, err := cfg.ImportProfile(doc, importName, options...)
+		if err != nil {
+			return err
+		}
+		if cfg.CurrentProfile == "" {
+			cfg.CurrentProfile = name
+		}
+		if err := updateClientConfiguration(cfg)(); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Imported profile %s\n", name)
+		if !cfg.HasAuth(name) {
+			fmt.Fprintf(cmd.OutOrStdout(), "The profile has no credentials, please login to use it.\n")
+		}
+
+		return nil
+	},
+}
+
+var (
+	exportCredentials bool
+	passphraseFile    string
+	importName        string
+	importInsecure    bool
+)
+
+// readPassphrase reads a passphrase from the first line of a file
+func readPassphrase(path string) (string, error) {
+	if path == "" {
+		return "", config.ErrPassphraseRequired
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(strings.SplitN(string(data), "\n", 2)[0], "\r"), nil
+}
+
+func init() {
+	profileExportCmd.Flags().BoolVar(&exportCredentials, "include-credentials", false, "include the encrypted credentials of the profile")
+	profileExportCmd.Flags().StringVar(&passphraseFile, "passphrase-file", "", "file holding the passphrase to encrypt the credentials")
+	profileImportCmd.Flags().StringVar(&passphraseFile, "passphrase-file", "", "file holding the passphrase to decrypt the credentials")
+	profileImportCmd.Flags().StringVar(&importName, "name", "", "name to give the imported profile")
+	profileImportCmd.Flags().BoolVar(&importInsecure, "allow-insecure", false, "allow the bundle to skip TLS verification or use a proxy")
+
+	profileCmd.AddCommand(profileCreateCmd, profileTrustCmd, profileExportCmd, profileImportCmd)
+	rootCmd.AddCommand(profileCmd)
+}
+
+// promptTrust shows the certificate details and asks the user whether to trust them
+func promptTrust(in io.Reader, out io.Writer) config.TrustPrompt {
+	return func(info config.TrustInfo) (bool, error) {
+		fmt.Fprintf(out, "The certificate of %s is not trusted by this system.\n", info.Endpoint)
+		fmt.Fprintf(out, "  Subject:     %s\n", info.Subject)
+		fmt.Fprintf(out, "  Issuer:      %s\n", info.Issuer)
+		fmt.Fprintf(out, "  Expires:     %s\n", info.NotAfter.Format("2006-01-02"))
+		fmt.Fprintf(out, "  Fingerprint: SHA256 %s\n", info.Fingerprint)
+		if info.PreviousPublicKeyPin != "" && info.PreviousPublicKeyPin != info.PublicKeyPin {
+			fmt.Fprintf(out, "WARNING: the server key has changed since it was last trusted.\n")
+		}
+		fmt.Fprint(out, "Do you trust this certificate? [y/N]: ")
+
+		answer, err := bufio.NewReader(in).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return false, err
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+
+		return answer == "y" || answer == "yes", nil
+	}
+}
@@ -0,0 +1,75 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import "time"
+
+// AccessToken describes a long-lived access token, which is exchanged for short-lived API tokens
+type AccessToken struct {
+	// Name is the name of the access token
+	Name string `json:"name"`
+	// Workspace is the workspace the token is scoped to, empty for a Wayfinder-scoped token
+	Workspace string `json:"workspace,omitempty"`
+	// Description describes what the token is used for
+	Description string `json:"description,omitempty"`
+	// Roles are the roles granted to the token
+	Roles []string `json:"roles,omitempty"`
+	// Created is the time the token was created or last rotated
+	Created int64 `json:"created,omitempty"`
+	// Expires is the time the token will expire, zero if it does not
+	Expires int64 `json:"expires,omitempty"`
+}
+
+// ExpiresAt returns the time the token expires, or the zero time if it does not
+func (a *AccessToken) ExpiresAt() time.Time {
+	if a.Expires == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(a.Expires, 0)
+}
+
+// ExpiresWithin checks if the token expires within the duration, or has already expired
+func (a *AccessToken) ExpiresWithin(d time.Duration) bool {
+	return a.Expires != 0 && time.Now().Add(d).After(a.ExpiresAt())
+}
+
+// AccessTokenRequest is a request to create or rotate an access token
+type AccessTokenRequest struct {
+	// Name is the name of the access token
+	Name string `json:"name,omitempty"`
+	// Description describes what the token is used for
+	Description string `json:"description,omitempty"`
+	// Roles are the roles to grant to the token
+	Roles []string `json:"roles,omitempty"`
+	// TTL is how long the token should be valid for, the server default is used when empty
+	TTL string `json:"ttl,omitempty"`
+}
+
+// IssuedAccessToken is an access token along with its secret value, only returned when the token
+// is created or rotated
+type IssuedAccessToken struct {
+	AccessToken `json:",inline"`
+	// Token is the access token to exchange for API tokens
+	Token string `json:"token"`
+}
+
+// AccessTokenList is a list of access tokens
+type AccessTokenList struct {
+	// Items are the access tokens
+	Items []AccessToken `json:"items"`
+}
@@ -58,6 +58,16 @@ type LocalUser struct {
 	Password string `json:"password,omitempty"`
 }
 
+// AuthorizationCodeExchange is a request to exchange a single sign-on authorization code for tokens
+type AuthorizationCodeExchange struct {
+	// Code is the authorization code returned to the client
+	Code string `json:"code"`
+	// CodeVerifier is the PKCE verifier of the code challenge sent when authorizing
+	CodeVerifier string `json:"codeVerifier"`
+	// RedirectURI is the redirect URI sent when authorizing
+	RedirectURI string `json:"redirectURI"`
+}
+
 // WhoAmI provides a description to who you are
 type WhoAmI struct {
 	// AuthMethod is the authentication method being used
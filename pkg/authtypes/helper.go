@@ -74,3 +74,16 @@ func IsAccessTokenScoped(claims *jwsutils.Claims) bool {
 
 	return utils.Contains(ScopeAccessToken, scopes)
 }
+
+// TokenAudiences are the audiences of the tokens issued by Wayfinder
+var TokenAudiences = []string{Audience, KubernetesAudience, RefreshTokenAudience}
+
+// NewTokenVerifier returns a verifier for Wayfinder tokens, accepting any of the TokenAudiences
+// unless the audiences are set in the options
+func NewTokenVerifier(options jwsutils.VerifierOptions) (*jwsutils.Verifier, error) {
+	if len(options.Audiences) == 0 {
+		options.Audiences = TokenAudiences
+	}
+
+	return jwsutils.NewVerifier(options)
+}
@@ -0,0 +1,226 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "github.com/appvia/wfclient/pkg/apis/core/v1alpha1"
+	types "github.com/appvia/wfclient/pkg/apitypes"
+	"github.com/appvia/wfclient/pkg/client/config"
+	jwsutils "github.com/appvia/wfclient/pkg/utils/jwt"
+)
+
+// AccessTokenOptions define an access token to create
+type AccessTokenOptions struct {
+	// Name is the name of the token
+	Name string
+	// Workspace scopes the token to a workspace, when empty the token is Wayfinder-scoped
+	Workspace corev1.WorkspaceKey
+	// Description describes what the token is used for
+	Description string
+	// Roles are the roles to grant to the token
+	Roles []string
+	// TTL is how long the token is valid for, the server default is used when zero
+	TTL time.Duration
+}
+
+// AccessTokenClient manages the access tokens of Wayfinder or a workspace
+type AccessTokenClient struct {
+	client Interface
+}
+
+// AccessTokens returns a client for managing access tokens
+func AccessTokens(client Interface) *AccessTokenClient {
+	return &AccessTokenClient{client: client}
+}
+
+// request returns a request for the access tokens of the workspace, or of Wayfinder when the
+// workspace is empty
+func (a *AccessTokenClient) request(ctx context.Context, workspace corev1.WorkspaceKey, suffix string) RestInterface {
+	endpoint := "/accesstokens" + suffix
+	if workspace != "" {
+		endpoint = "/workspaces/{workspace}" + endpoint
+	}
+
+	return a.client.Request().Context(ctx).Endpoint(endpoint).Workspace(workspace)
+}
+
+// Create creates an access token, returning it along with its secret value
+func (a *AccessTokenClient) Create(ctx context.Context, options AccessTokenOptions) (*types.IssuedAccessToken, error) {
+	if options.Name == "" {
+		return nil, errors.New("access token name is required")
+	}
+
+	issued := &types.IssuedAccessToken{}
+	if err := a.request(ctx, options.Workspace, "").
+		Payload(&types.AccessTokenRequest{
+			Name:        options.Name,
+			Description: options.Description,
+			Roles:       options.Roles,
+			TTL:         formatTTL(options.TTL),
+		}).
+		Result(issued).
+		Post().
+		Error(); err != nil {
+		return nil, fmt.Errorf("failed to create access token %s: %w", options.Name, err)
+	}
+
+	return issued, nil
+}
+
+// List returns the access tokens of the workspace, or the Wayfinder-scoped tokens when the
+// workspace is empty
+func (a *AccessTokenClient) List(ctx context.Context, workspace corev1.WorkspaceKey) ([]types.AccessToken, error) {
+	list := &types.AccessTokenList{}
+	if err := a.request(ctx, workspace, "").Result(list).Get().Error(); err != nil {
+		return nil, err
+	}
+
+	return list.Items, nil
+}
+
+// Revoke deletes the access token, after which it can no longer be exchanged
+func (a *AccessTokenClient) Revoke(ctx context.Context, workspace corev1.WorkspaceKey, name string) error {
+	if name == "" {
+		return errors.New("access token name is required")
+	}
+
+	return a.request(ctx, workspace, "/{name}").Name(name).Delete().Error()
+}
+
+// Rotate issues a new secret value for the access token, invalidating the previous one. The
+// server default validity is used when the TTL is zero.
+func (a *AccessTokenClient) Rotate(ctx context.Context, workspace corev1.WorkspaceKey, name string, ttl time.Duration) (*types.IssuedAccessToken, error) {
+	if name == "" {
+		return nil, errors.New("access token name is required")
+	}
+
+	issued := &types.IssuedAccessToken{}
+	if err := a.request(ctx, workspace, "/{name}/rotate").
+		Name(name).
+		Payload(&types.AccessTokenRequest{TTL: formatTTL(ttl)}).
+		Result(issued).
+		Post().
+		Error(); err != nil {
+		return nil, fmt.Errorf("failed to rotate access token %s: %w", name, err)
+	}
+
+	return issued, nil
+}
+
+// RotateAuthInfo rotates the access token and stores the new value in the named user of the
+// client configuration, persisting it with save. As the previous token stops working once rotated,
+// save is required - usually the update handler the client was created with.
+func (a *AccessTokenClient) RotateAuthInfo(ctx context.Context, workspace corev1.WorkspaceKey, name, authInfo string, ttl time.Duration, save UpdateHandlerFunc) (*types.IssuedAccessToken, error) {
+	if save == nil {
+		return nil, errors.New("a save handler is required to store the rotated access token")
+	}
+	cfg := a.client.Config()
+	if !cfg.HasAuthInfo(authInfo) {
+		return nil, fmt.Errorf("user %q does not exist", authInfo)
+	}
+
+	issued, err := a.Rotate(ctx, workspace, name, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	// @step: the api token is cleared so it is exchanged using the new access token on next use
+	cfg.AuthInfos[authInfo].Identity = &config.Identity{RefreshToken: issued.Token}
+	cfg.AuthInfos[authInfo].Token = nil
+
+	if err := save(); err != nil {
+		return issued, fmt.Errorf("access token rotated but the configuration could not be saved: %w", err)
+	}
+
+	return issued, nil
+}
+
+// formatTTL returns the TTL as sent to the server, empty for the server default
+func formatTTL(ttl time.Duration) string {
+	if ttl <= 0 {
+		return ""
+	}
+
+	return ttl.String()
+}
+
+// TokenExpiryWarning describes a stored token which is close to expiry
+type TokenExpiryWarning struct {
+	// AuthInfo is the name of the user holding the token
+	AuthInfo string
+	// Field is the field of the user holding the token
+	Field string
+	// Expires is when the token expires
+	Expires time.Time
+}
+
+// Expired checks if the token has already expired
+func (w TokenExpiryWarning) Expired() bool {
+	return !w.Expires.After(time.Now())
+}
+
+// String describes the warning
+func (w TokenExpiryWarning) String() string {
+	if w.Expired() {
+		return fmt.Sprintf("the %s of user %s expired at %s", w.Field, w.AuthInfo, w.Expires.Format(time.RFC3339))
+	}
+
+	return fmt.Sprintf("the %s of user %s expires in %s", w.Field, w.AuthInfo, time.Until(w.Expires).Round(time.Minute))
+}
+
+// TokenExpiryHandler is called for each stored token close to expiry
+type TokenExpiryHandler func(TokenExpiryWarning)
+
+// CheckTokenExpiry returns a warning for each long-lived token stored in the configuration which
+// expires within the duration, such as access tokens and refresh tokens. Short-lived API tokens
+// are refreshed automatically so are not included.
+func CheckTokenExpiry(cfg *config.Config, within time.Duration) []TokenExpiryWarning {
+	var warnings []TokenExpiryWarning
+
+	check := func(name, field, token string) {
+		if token == "" {
+			return
+		}
+		claims, err := jwsutils.NewClaimsFromRawToken(token)
+		if err != nil {
+			return
+		}
+		if expiry, found := claims.GetExpiry(); found && time.Now().Add(within).After(expiry) {
+			warnings = append(warnings, TokenExpiryWarning{AuthInfo: name, Field: field, Expires: expiry})
+		}
+	}
+
+	for _, name := range cfg.ListAuthInfos() {
+		auth := cfg.AuthInfos[name]
+		if auth == nil {
+			continue
+		}
+		if auth.Token != nil {
+			check(name, "token", *auth.Token)
+		}
+		if auth.Identity != nil {
+			check(name, "refresh-token", auth.Identity.RefreshToken)
+		}
+	}
+
+	return warnings
+}
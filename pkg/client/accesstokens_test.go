@@ -0,0 +1,159 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/appvia/wfclient/pkg/apitypes"
+	"github.com/appvia/wfclient/pkg/client/config"
+)
+
+func TestAccessTokenCreate(t *testing.T) {
+	var paths []string
+	updates := 0
+	c := newTestLoginClient(t, &updates, func(req *http.Request) (int, interface{}) {
+		paths = append(paths, req.Method+" "+req.URL.Path)
+
+		request := &types.AccessTokenRequest{}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(request))
+		assert.Equal(t, "720h0m0s", request.TTL)
+		assert.Equal(t, []string{"deployer"}, request.Roles)
+
+		return http.StatusOK, &types.IssuedAccessToken{
+			AccessToken: types.AccessToken{Name: request.Name, Roles: request.Roles},
+			Token:       "secret",
+		}
+	})
+	c.Config().AddAuthInfo("test", &config.AuthInfo{Token: new(string)})
+
+	options := AccessTokenOptions{Name: "ci", Workspace: "ops", Roles: []string{"deployer"}, TTL: 30 * 24 * time.Hour}
+	issued, err := AccessTokens(c).Create(context.Background(), options)
+	require.NoError(t, err)
+	assert.Equal(t, "ci", issued.Name)
+	assert.Equal(t, "secret", issued.Token)
+
+	options.Workspace = ""
+	_, err = AccessTokens(c).Create(context.Background(), options)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"POST /api/v2/workspaces/ops/accesstokens", "POST /api/v2/accesstokens"}, paths)
+}
+
+func TestAccessTokenListAndRevoke(t *testing.T) {
+	expires := time.Now().Add(48 * time.Hour).Unix()
+	var paths []string
+	updates := 0
+	c := newTestLoginClient(t, &updates, func(req *http.Request) (int, interface{}) {
+		paths = append(paths, req.Method+" "+req.URL.Path)
+		if req.Method == http.MethodGet {
+			return http.StatusOK, &types.AccessTokenList{Items: []types.AccessToken{{Name: "ci", Workspace: "ops", Expires: expires}, {Name: "forever", Workspace: "ops"}}}
+		}
+		return http.StatusOK, nil
+	})
+	c.Config().AddAuthInfo("test", &config.AuthInfo{Token: new(string)})
+
+	tokens, err := AccessTokens(c).List(context.Background(), "ops")
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+	assert.True(t, tokens[0].ExpiresWithin(7*24*time.Hour))
+	assert.False(t, tokens[0].ExpiresWithin(time.Hour))
+	assert.False(t, tokens[1].ExpiresWithin(365*24*time.Hour))
+	assert.True(t, tokens[1].ExpiresAt().IsZero())
+
+	require.NoError(t, AccessTokens(c).Revoke(context.Background(), "ops", "ci"))
+	assert.Error(t, AccessTokens(c).Revoke(context.Background(), "ops", ""))
+
+	assert.Equal(t, []string{"GET /api/v2/workspaces/ops/accesstokens", "DELETE /api/v2/workspaces/ops/accesstokens/ci"}, paths)
+}
+
+func TestAccessTokenRotateAuthInfo(t *testing.T) {
+	rotated := makeTestScopedJWT(t, "wayfinder:auth:exchange")
+	updates := 0
+	c := newTestLoginClient(t, &updates, func(req *http.Request) (int, interface{}) {
+		assert.Equal(t, "/api/v2/accesstokens/ci/rotate", req.URL.Path)
+		return http.StatusOK, &types.IssuedAccessToken{AccessToken: types.AccessToken{Name: "ci"}, Token: rotated}
+	})
+	c.Config().AddAuthInfo("test", &config.AuthInfo{Token: new(string)})
+	c.Config().AddAuthInfo("ci", &config.AuthInfo{Identity: &config.Identity{RefreshToken: "old", Token: "api-token"}})
+
+	saves := 0
+	save := func() error {
+		saves++
+		return nil
+	}
+
+	_, err := AccessTokens(c).RotateAuthInfo(context.Background(), "", "ci", "missing", time.Hour, save)
+	assert.Error(t, err)
+	// the token is never rotated if it cannot be saved
+	_, err = AccessTokens(c).RotateAuthInfo(context.Background(), "", "ci", "ci", time.Hour, nil)
+	assert.Error(t, err)
+	assert.Equal(t, "old", c.Config().AuthInfos["ci"].Identity.RefreshToken)
+
+	issued, err := AccessTokens(c).RotateAuthInfo(context.Background(), "", "ci", "ci", time.Hour, save)
+	require.NoError(t, err)
+	assert.Equal(t, rotated, issued.Token)
+	assert.Equal(t, 1, saves)
+
+	_, err = AccessTokens(c).RotateAuthInfo(context.Background(), "", "ci", "ci", time.Hour, func() error {
+		return errors.New("read-only")
+	})
+	assert.ErrorContains(t, err, "could not be saved")
+
+	identity := c.Config().AuthInfos["ci"].Identity
+	assert.Equal(t, rotated, identity.RefreshToken)
+	assert.Empty(t, identity.Token)
+	assert.True(t, identity.IsExchangeToken())
+}
+
+func TestTokenExpiryWarning(t *testing.T) {
+	sign := func(expires time.Time) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"exp": expires.Unix()}).SignedString([]byte("secret"))
+		require.NoError(t, err)
+		return token
+	}
+	soon, later, expired := sign(time.Now().Add(3*24*time.Hour)), sign(time.Now().Add(60*24*time.Hour)), sign(time.Now().Add(-time.Hour))
+
+	cfg := config.NewEmpty()
+	cfg.AddAuthInfo("soon", &config.AuthInfo{Identity: &config.Identity{RefreshToken: soon, Token: expired}})
+	cfg.AddAuthInfo("later", &config.AuthInfo{Token: &later})
+	cfg.AddAuthInfo("expired", &config.AuthInfo{Token: &expired})
+
+	var warnings []TokenExpiryWarning
+	_, err := New(cfg, UseTokenExpiryWarning(7*24*time.Hour, func(w TokenExpiryWarning) {
+		warnings = append(warnings, w)
+	}))
+	require.NoError(t, err)
+
+	require.Len(t, warnings, 2)
+	assert.Equal(t, "expired", warnings[0].AuthInfo)
+	assert.True(t, warnings[0].Expired())
+	assert.Contains(t, warnings[0].String(), "expired at")
+	assert.Equal(t, "soon", warnings[1].AuthInfo)
+	assert.Equal(t, "refresh-token", warnings[1].Field)
+	assert.False(t, warnings[1].Expired())
+	assert.Contains(t, warnings[1].String(), "expires in 72h0m0s")
+}
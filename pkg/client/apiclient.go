@@ -19,8 +19,6 @@ package client
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
-	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -28,10 +26,12 @@ import (
 	"strings"
 	"time"
 
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	corev1 "github.com/appvia/wfclient/pkg/apis/core/v1alpha1"
 	"github.com/appvia/wfclient/pkg/client/config"
 	"github.com/appvia/wfclient/pkg/common"
-	"github.com/appvia/wfclient/pkg/utils/httputils"
 	"github.com/appvia/wfclient/pkg/utils/retry"
 	"github.com/appvia/wfclient/pkg/utils/validation"
 	"github.com/appvia/wfclient/pkg/version"
@@ -74,6 +74,10 @@ type apiClient struct {
 
 	// customRequestDo is a function to perform the request, exposed so we can override it when testing.
 	customRequestDo RequestDo
+	// httpClients caches the http clients shared by the requests of the client
+	httpClients *httpClientCache
+	// har optionally records the requests to an HTTP archive
+	har *HARRecorder
 }
 
 func (a *apiClient) Profile() string {
@@ -97,7 +101,33 @@ func (a *apiClient) makeAPIEndpoint() (*config.Server, error) {
 
 	}
 
-	return server, nil
+	// @step: apply any overrides, such as from the environment
+	return a.cfg.GetEffectiveServer(a.Profile()), nil
+}
+
+// serverEndpoint returns the endpoint of the profile's server, if known
+func (a *apiClient) serverEndpoint() string {
+	if server := a.cfg.GetServer(a.Profile()); server != nil {
+		return server.Endpoint
+	}
+
+	return ""
+}
+
+// forgetCachedToken clears the cached exec credential or exchanged service account token for the
+// profile, if it uses one
+func (a *apiClient) forgetCachedToken() {
+	if a.unauthenticated || a.authtoken != "" {
+		return
+	}
+	auth := a.cfg.AuthInfos[a.Profile()]
+	switch {
+	case auth == nil:
+	case auth.Exec != nil:
+		forgetExecToken(auth.Exec, a.serverEndpoint())
+	case auth.ServiceAccount != nil:
+		forgetServiceAccountToken(auth.ServiceAccount, a.serverEndpoint())
+	}
 }
 
 func (a *apiClient) reqCtx() context.Context {
@@ -124,9 +154,10 @@ func (a *apiClient) handleRequest(method string) RestInterface {
 		}
 
 		if a.hc == nil && a.customRequestDo == nil {
-			a.hc = a.makeHTTPClient(server.CACertificate)
-			if a.follow {
-				a.hc.Timeout = 0 * time.Second
+			if a.hc, err = a.makeHTTPClient(server); err != nil {
+				a.ferror = err
+
+				return a.ferror
 			}
 		}
 
@@ -145,6 +176,15 @@ func (a *apiClient) handleRequest(method string) RestInterface {
 		if server.CACertificate != "" {
 			logFields["customCA"] = true
 		}
+		if server.InsecureSkipTLSVerify {
+			logFields["insecure"] = true
+		}
+		if server.ProxyURL != "" {
+			logFields["proxy"] = server.ProxyURL
+		}
+		if server.HasClientCertificate() {
+			logFields["clientCertificate"] = true
+		}
 
 		common.Log(ctx).WithFields(logFields).Debug("API request")
 
@@ -178,6 +218,11 @@ func (a *apiClient) handleRequest(method string) RestInterface {
 
 		common.Log(ctx).WithFields(logFields).WithField("reponseCode", resp.StatusCode).WithField("duration", time.Since(now).String()).Debug("API request: Complete")
 
+		// @step: a rejected exec credential or exchanged token should not be reused
+		if resp.StatusCode == http.StatusUnauthorized {
+			a.forgetCachedToken()
+		}
+
 		return a.handleResponse(resp)
 	}()
 	if err != nil {
@@ -213,6 +258,14 @@ func (a *apiClient) makeRequest(method, url string) (*http.Response, error) {
 }
 
 func (a *apiClient) do(req *http.Request) (*http.Response, error) {
+	if a.har != nil {
+		return a.har.record(req, a.follow, a.roundTrip)
+	}
+
+	return a.roundTrip(req)
+}
+
+func (a *apiClient) roundTrip(req *http.Request) (*http.Response, error) {
 	if a.customRequestDo != nil {
 		return a.customRequestDo(req)
 	}
@@ -245,6 +298,17 @@ func (a *apiClient) AddAuthorization(req *http.Request) error {
 		return nil
 	}
 
+	// @step: a token file override replaces the credentials of the profile
+	if file := a.cfg.GetOverrides().TokenFile; file != "" {
+		token, err := ReadTokenFile(file)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		return nil
+	}
+
 	auth := a.cfg.AuthInfos[a.Profile()]
 
 	switch {
@@ -267,6 +331,22 @@ func (a *apiClient) AddAuthorization(req *http.Request) error {
 		}
 
 		req.Header.Set("Authorization", "Bearer "+auth.Identity.Token)
+
+	case auth.Exec != nil:
+		token, err := GetExecToken(req.Context(), auth.Exec, a.serverEndpoint())
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token)
+
+	case auth.ServiceAccount != nil:
+		token, err := a.serviceAccountToken(req.Context(), auth.ServiceAccount)
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token)
 	}
 
 	return nil
@@ -322,39 +402,94 @@ func (a *apiClient) handleResponse(resp *http.Response) error {
 }
 
 func (a *apiClient) decodeError(resp *http.Response, apiError *APIError) {
-	if resp.Body != nil {
-		switch resp.StatusCode {
-		case http.StatusBadRequest:
-			vError := &validation.Error{}
-			if err := a.makeResult(resp, vError); err != nil {
-				common.Log(a.reqCtx()).WithError(err).Debug("response cannot be decoded into a validation error")
-				return
-			}
-			apiError.Message = vError.Error()
-			apiError.Validation = vError
+	defer func() {
+		if apiError.Reason == "" {
+			apiError.Reason = reasonForStatusCode(apiError.Code)
+		}
+	}()
+
+	if resp.Body == nil {
+		return
+	}
+	if err := a.makeResult(resp, nil); err != nil {
+		common.Log(a.reqCtx()).WithError(err).Debug("response body cannot be read")
+		return
+	}
+	content := a.body.Bytes()
+	if len(content) == 0 {
+		return
+	}
+
+	// @step: the server may return a kubernetes status, which we translate into our errors
+	if status, ok := decodeStatus(content); ok {
+		apiError.Reason = status.Reason
+		apiError.Message = status.Message
+		if status.Details != nil {
+			apiError.Causes = status.Details.Causes
+		}
+		switch converted := validation.APIStatusToValidationError(kerrors.FromObject(status)).(type) {
+		case validation.Error:
+			apiError.Validation = &converted
+			apiError.Message = converted.Error()
+		case validation.ErrDependencyViolation:
+			apiError.DependencyViolation = &converted
+			apiError.Message = converted.Error()
+			apiError.Reason = ReasonDependencyViolation
+		}
+		return
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadRequest:
+		vError := &validation.Error{}
+		if err := json.Unmarshal(content, vError); err != nil {
+			common.Log(a.reqCtx()).WithError(err).Debug("response cannot be decoded into a validation error")
 			return
-		case http.StatusConflict:
-			// Two different types of conflict are represented by 409 - a conflict when trying to
-			// write an object to k8s and a conflict with a dependency blocking deletion
-			if resp.Header.Get("x-wayfinder-objectmodified") == "true" {
-				apiError.Message = ObjectModifiedError
-			} else {
-				err := &validation.ErrDependencyViolation{}
-				if err := a.makeResult(resp, err); err != nil {
-					common.Log(a.reqCtx()).WithError(err).Debugf("response cannot be decoded into a validation error - %v", a.Body())
-					return
-				}
-				apiError.Message = err.Error()
-				apiError.DependencyViolation = err
-			}
+		}
+		apiError.Message = vError.Error()
+		apiError.Validation = vError
+		apiError.Reason = metav1.StatusReasonInvalid
+		return
+	case http.StatusConflict:
+		// Two different types of conflict are represented by 409 - a conflict when trying to
+		// write an object to k8s and a conflict with a dependency blocking deletion
+		if resp.Header.Get("x-wayfinder-objectmodified") == "true" {
+			apiError.Message = ObjectModifiedError
+			apiError.Reason = metav1.StatusReasonConflict
+			apiError.Causes = []metav1.StatusCause{{Type: CauseTypeObjectModified, Message: ObjectModifiedError}}
 			return
 		}
-
-		err := a.makeResult(resp, apiError)
-		if err != nil && err != io.EOF {
-			common.Log(a.reqCtx()).WithError(err).Debug("response cannot be decoded")
+		err := &validation.ErrDependencyViolation{}
+		if jerr := json.Unmarshal(content, err); jerr != nil {
+			common.Log(a.reqCtx()).WithError(jerr).Debugf("response cannot be decoded into a validation error - %s", common.RedactJSON(content))
+			return
 		}
+		if len(err.Dependents) > 0 {
+			apiError.Message = err.Error()
+			apiError.DependencyViolation = err
+			apiError.Reason = ReasonDependencyViolation
+			return
+		}
+	}
+
+	if err := json.Unmarshal(content, apiError); err != nil {
+		common.Log(a.reqCtx()).WithError(err).Debug("response cannot be decoded")
+	}
+	// the body may override the details of the request, but not the status
+	apiError.Code = resp.StatusCode
+}
+
+// decodeStatus returns the kubernetes status if the content is one
+func decodeStatus(content []byte) (*metav1.Status, bool) {
+	status := &metav1.Status{}
+	if err := json.Unmarshal(content, status); err != nil {
+		return nil, false
 	}
+	if status.Kind != "Status" || status.Status != metav1.StatusFailure {
+		return nil, false
+	}
+
+	return status, true
 }
 
 // makeResult is responsible for reading the resulting payload
@@ -374,7 +509,7 @@ func (a *apiClient) makeResult(resp *http.Response, data interface{}) error {
 
 	a.body.Write(content)
 
-	common.Log(a.reqCtx()).WithField("body", a.body.String()).Trace("we received the following response from api")
+	common.Log(a.reqCtx()).WithField("body", common.RedactedJSON(content)).Trace("we received the following response from api")
 
 	if data == nil || len(content) == 0 {
 		common.Log(a.reqCtx()).Trace("no result has been set to save the payload")
@@ -395,7 +530,7 @@ func (a *apiClient) makePayload() (io.Reader, error) {
 	if err := json.NewEncoder(b).Encode(a.payload); err != nil {
 		return nil, err
 	}
-	common.Log(a.reqCtx()).WithField("payload", b.String()).Trace("using the attached payload for request")
+	common.Log(a.reqCtx()).WithField("payload", common.RedactedJSON(b.Bytes())).Trace("using the attached payload for request")
 
 	return b, nil
 }
@@ -471,27 +606,14 @@ func (a *apiClient) Parameters(params ...ParameterFunc) RestInterface {
 	return a
 }
 
-// makeHTTPClient is responsible for creating the http client
-func (a *apiClient) makeHTTPClient(cacert string) *http.Client {
-	if cacert == "" {
-		return httputils.DefaultHTTPClient
+// makeHTTPClient is responsible for retrieving the http client for the server, reusing the client's
+// cached http clients so connections are kept alive across requests
+func (a *apiClient) makeHTTPClient(server *config.Server) (*http.Client, error) {
+	if a.httpClients == nil {
+		a.httpClients = newHTTPClientCache(nil)
 	}
 
-	rootCAs, _ := x509.SystemCertPool()
-	if rootCAs == nil {
-		rootCAs = x509.NewCertPool()
-	}
-
-	if ok := rootCAs.AppendCertsFromPEM([]byte(cacert)); !ok {
-		common.Log(a.reqCtx()).Debug("no certs appended, using system certs only")
-	}
-
-	custTransport := httputils.DefaultTransport.Clone()
-	if custTransport.TLSClientConfig == nil {
-		custTransport.TLSClientConfig = &tls.Config{}
-	}
-	custTransport.TLSClientConfig.RootCAs = rootCAs
-	return httputils.NewDefaultHTTPClient(custTransport)
+	return a.httpClients.clientFor(a.cfg.Profiles[a.Profile()].Server, server, a.follow)
 }
 
 func (a *apiClient) HasParameter(key string) (string, bool) {
@@ -591,6 +713,8 @@ func (a *apiClient) Duplicate() RestInterface {
 		urlManager:      a.urlManager.Duplicate(),
 		warningHandler:  a.warningHandler,
 		customRequestDo: a.customRequestDo,
+		httpClients:     a.httpClients,
+		har:             a.har,
 	}
 
 	return n
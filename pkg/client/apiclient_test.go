@@ -0,0 +1,58 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appv2beta1 "github.com/appvia/wfclient/pkg/apis/app/v2beta1"
+	"github.com/appvia/wfclient/pkg/client/config"
+	"github.com/appvia/wfclient/pkg/common"
+)
+
+func TestPayloadTraceLoggingRedactsVars(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.SetOutput(out)
+	logger.SetLevel(logrus.TraceLevel)
+	common.SetLogProvider(func() common.Logger { return logger })
+	defer common.SetLogProvider(func() common.Logger { return logrus.StandardLogger() })
+
+	updates := 0
+	c := newTestLoginClient(t, &updates, func(req *http.Request) (int, interface{}) {
+		return http.StatusOK, map[string]string{}
+	})
+	token := "api-token"
+	c.Config().AddAuthInfo("test", &config.AuthInfo{Token: &token})
+	request := &appv2beta1.AppDeploymentRequest{
+		TargetEnv: "dev",
+		Vars:      map[string]string{"db-url": "postgres://admin:var-secret@db"},
+		Secrets:   map[string]string{"api-key": "secret-value"},
+	}
+	require.NoError(t, c.Request().Endpoint("/deploy").Payload(request).Create().Error())
+
+	assert.Contains(t, out.String(), "using the attached payload")
+	assert.Contains(t, out.String(), "db-url")
+	assert.NotContains(t, out.String(), "var-secret")
+	assert.NotContains(t, out.String(), "secret-value")
+}
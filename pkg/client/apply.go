@@ -0,0 +1,410 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	appv2beta1 "github.com/appvia/wfclient/pkg/apis/app/v2beta1"
+	corev1 "github.com/appvia/wfclient/pkg/apis/core/v1alpha1"
+	"github.com/appvia/wfclient/pkg/common"
+	"github.com/appvia/wfclient/pkg/utils"
+	"github.com/appvia/wfclient/pkg/utils/validation"
+)
+
+// ApplyOptions controls how a set of objects is applied by ApplyAll
+type ApplyOptions struct {
+	// Prune deletes any live objects matching Selector which are not in the applied set
+	Prune bool
+	// DryRun performs all operations as a server-side dry run
+	DryRun bool
+	// Selector identifies the live objects managed by this set, required when pruning
+	Selector labels.Selector
+}
+
+// ApplyResult describes the outcome of an ApplyAll
+type ApplyResult struct {
+	// Applied is the list of objects applied, in the order they were applied
+	Applied []ObjectKey
+	// Pruned is the list of objects deleted as they were no longer in the set
+	Pruned []ObjectKey
+	// Warnings are any warnings returned by the server during the apply
+	Warnings []validation.Warning
+}
+
+// ErrApplyCycle indicates the objects to apply reference each other in a loop
+var ErrApplyCycle = errors.New("objects to apply contain a reference cycle")
+
+// ApplyAll applies the provided objects to Wayfinder using server-side apply, ordering them so
+// that referenced objects (e.g. plans, app definition versions, clusters, app environments) are
+// applied before the objects which reference them. If opts.Prune is set, live objects of the same
+// types matching opts.Selector which are not in the set are deleted once the apply completes.
+func ApplyAll(ctx context.Context, wf WFClient, objects []Object, opts ApplyOptions) (*ApplyResult, error) {
+	if opts.Prune && (opts.Selector == nil || opts.Selector.Empty()) {
+		return nil, errors.New("a label selector must be provided when pruning")
+	}
+
+	plan, err := PlanApply(objects)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ApplyResult{}
+	var mu sync.Mutex
+	handler := func(_ context.Context, warnings []validation.Warning) {
+		mu.Lock()
+		defer mu.Unlock()
+		result.Warnings = append(result.Warnings, warnings...)
+	}
+
+	for _, obj := range plan {
+		common.Log(ctx).WithField("object", applyNodeID(obj)).Debug("applying object")
+
+		if err := wf.Update(ctx, obj,
+			WithApply(true),
+			WithDryRun(opts.DryRun),
+			WithWarningHandler(handler),
+		); err != nil {
+			return result, fmt.Errorf("failed to apply %s: %w", applyNodeID(obj), err)
+		}
+		result.Applied = append(result.Applied, ObjectKeyFromObject(obj))
+	}
+
+	if !opts.Prune {
+		return result, nil
+	}
+
+	pruned, err := prune(ctx, wf, objects, opts)
+	result.Pruned = pruned
+
+	return result, err
+}
+
+// PlanApply returns the provided objects in a safe order to apply them, where each object comes
+// after any objects in the set which it references. Objects with no relationship to each other
+// retain their original relative order.
+func PlanApply(objects []Object) ([]Object, error) {
+	// nodes maps every ID an object may be referenced by to the indexes of the objects it
+	// identifies
+	nodes := make(map[string][]int, len(objects))
+	seen := make(map[string]bool, len(objects))
+	var planKinds []string
+	for i, obj := range objects {
+		id := applyNodeID(obj)
+		if seen[id] {
+			return nil, fmt.Errorf("%s is included more than once", id)
+		}
+		seen[id] = true
+		nodes[id] = append(nodes[id], i)
+
+		// versioned objects can be referenced without a version (e.g. an app definition by an app
+		// env) or through a plan reference, which does not identify the kind
+		if corev1.IsVersioned(obj) {
+			kind := objectKind(obj)
+			if !utils.Contains(kind, planKinds) {
+				planKinds = append(planKinds, kind)
+			}
+			nodes[pruneID(obj)] = append(nodes[pruneID(obj)], i)
+			pid := planID(kind, corev1.GetVersionedObjectName(obj), corev1.GetVersion(obj))
+			nodes[pid] = append(nodes[pid], i)
+		}
+	}
+
+	// edges[i] lists the indexes of the objects that depend on objects[i]
+	edges := make([][]int, len(objects))
+	inDegree := make([]int, len(objects))
+	for i, obj := range objects {
+		for _, ref := range references(obj, planKinds) {
+			for _, j := range nodes[ref] {
+				if j == i {
+					continue
+				}
+				edges[j] = append(edges[j], i)
+				inDegree[i]++
+			}
+		}
+	}
+
+	var ready []int
+	for i := range objects {
+		if inDegree[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	ordered := make([]Object, 0, len(objects))
+	for len(ready) > 0 {
+		sort.Ints(ready)
+		i := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, objects[i])
+
+		for _, j := range edges[i] {
+			inDegree[j]--
+			if inDegree[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+	}
+
+	if len(ordered) != len(objects) {
+		var cyclic []string
+		for i, obj := range objects {
+			if inDegree[i] > 0 {
+				cyclic = append(cyclic, applyNodeID(obj))
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrApplyCycle, strings.Join(cyclic, ", "))
+	}
+
+	return ordered, nil
+}
+
+// prune removes any live objects of the applied types which match the selector but are not in the
+// applied set
+func prune(ctx context.Context, wf WFClient, objects []Object, opts ApplyOptions) ([]ObjectKey, error) {
+	type listKey struct {
+		kind      string
+		workspace corev1.WorkspaceKey
+	}
+
+	desired := map[string]bool{}
+	sources := map[listKey]Object{}
+	var order []listKey
+	for _, obj := range objects {
+		desired[pruneID(obj)] = true
+		k := listKey{kind: objectKind(obj), workspace: corev1.Workspace(obj)}
+		if _, found := sources[k]; !found {
+			sources[k] = obj
+			order = append(order, k)
+		}
+	}
+
+	seen := map[string]bool{}
+	var candidates []Object
+	for _, k := range order {
+		// list using the type information from the applied object, as the list type may not have
+		// it populated
+		src := sources[k]
+		list := src.ListType()
+		req := wf.ResourceRequest(ctx, src)
+		if k.workspace != "" {
+			req = req.Workspace(k.workspace)
+		}
+		if err := req.Result(list).Get().Error(); err != nil {
+			return nil, fmt.Errorf("failed to list %s for pruning: %w", k.kind, err)
+		}
+
+		for _, live := range list.GetItems() {
+			if !opts.Selector.Matches(labels.Set(live.GetLabels())) {
+				continue
+			}
+			id := pruneID(live)
+			if desired[id] || seen[id] {
+				continue
+			}
+			seen[id] = true
+			live.GetObjectKind().SetGroupVersionKind(src.GetObjectKind().GroupVersionKind())
+			candidates = append(candidates, live)
+		}
+	}
+
+	// delete dependents before the objects they depend on
+	ordered, err := PlanApply(candidates)
+	if err != nil {
+		ordered = candidates
+	}
+
+	var pruned []ObjectKey
+	for i := len(ordered) - 1; i >= 0; i-- {
+		obj := ordered[i]
+		key := ObjectKeyFromObject(obj)
+
+		common.Log(ctx).WithField("object", applyNodeID(obj)).Debug("pruning object")
+
+		var err error
+		if corev1.IsVersioned(obj) {
+			key.Version = ""
+			key.Name = corev1.GetVersionedObjectName(obj)
+			err = wf.DeleteAllVersions(ctx, key, obj.ListType(), WithDryRun(opts.DryRun))
+		} else {
+			err = wf.Delete(ctx, obj, WithDryRun(opts.DryRun))
+		}
+		if err != nil && !IsNotFound(err) {
+			return pruned, fmt.Errorf("failed to prune %s: %w", applyNodeID(obj), err)
+		}
+		pruned = append(pruned, key)
+	}
+
+	return pruned, nil
+}
+
+// pruneID identifies an object for pruning - all versions of a versioned object are considered
+// to be the same object, so we never prune older versions of an object still in the set
+func pruneID(obj Object) string {
+	return nodeID(objectKind(obj), corev1.Workspace(obj), corev1.GetVersionedObjectName(obj), "")
+}
+
+// applyNodeID uniquely identifies an object within a set to apply
+func applyNodeID(obj Object) string {
+	return nodeID(objectKind(obj), corev1.Workspace(obj), corev1.GetVersionedObjectName(obj), corev1.GetVersion(obj))
+}
+
+func nodeID(kind string, workspace corev1.WorkspaceKey, name string, version corev1.ObjectVersion) string {
+	id := kind + "/"
+	if workspace != "" {
+		id += workspace.Key() + "/"
+	}
+	id += name
+	if version != "" {
+		id += "@" + version.String()
+	}
+	return id
+}
+
+// planID identifies a versioned object of the given kind by the name and version used in a PlanRef
+func planID(kind, name string, version corev1.ObjectVersion) string {
+	return "plan:" + kind + "/" + name + "@" + version.String()
+}
+
+// objectKind returns the kind of the object, from its type information if set or its Go type
+func objectKind(obj Object) string {
+	if kind := obj.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+	return reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+}
+
+// references returns the node IDs of all objects referenced from the spec of the provided object
+// through the known reference types. As plan references do not identify the kind, they resolve to
+// each of the provided plan kinds.
+func references(obj Object, planKinds []string) []string {
+	var refs []string
+	// an app env belongs to every version of its app definition
+	if env, ok := obj.(*appv2beta1.AppEnv); ok && env.Spec.Application != "" {
+		refs = append(refs, nodeID(appv2beta1.AppDefinitionKind, "", env.Spec.Application, ""))
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(obj))
+	if v.Kind() != reflect.Struct {
+		return refs
+	}
+	spec := v.FieldByName("Spec")
+	if !spec.IsValid() {
+		return refs
+	}
+
+	walkReferences(spec, func(ref interface{}) {
+		if r, ok := ref.(corev1.PlanRef); ok {
+			if r.Empty() {
+				return
+			}
+			for _, kind := range planKinds {
+				refs = append(refs, planID(kind, r.Name, r.Version))
+			}
+			return
+		}
+		if id := referenceID(obj, ref); id != "" {
+			refs = append(refs, id)
+		}
+	})
+
+	return refs
+}
+
+var (
+	planRefType          = reflect.TypeOf(corev1.PlanRef{})
+	appEnvRefType        = reflect.TypeOf(corev1.AppEnvRef{})
+	clusterRefType       = reflect.TypeOf(corev1.ClusterRef{})
+	cloudAccessConfigRef = reflect.TypeOf(corev1.CloudAccessConfigRef{})
+	ownershipType        = reflect.TypeOf(corev1.Ownership{})
+)
+
+// walkReferences calls fn with every known reference found within v
+func walkReferences(v reflect.Value, fn func(interface{})) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			walkReferences(v.Elem(), fn)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walkReferences(v.Index(i), fn)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			walkReferences(iter.Value(), fn)
+		}
+	case reflect.Struct:
+		switch v.Type() {
+		case planRefType, appEnvRefType, clusterRefType, cloudAccessConfigRef, ownershipType:
+			fn(v.Interface())
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				walkReferences(v.Field(i), fn)
+			}
+		}
+	}
+}
+
+// referenceID converts a reference held by the holder object into the node ID of the referenced
+// object. Plan references are resolved by references, as they may refer to several kinds.
+func referenceID(holder Object, ref interface{}) string {
+	// references without a workspace refer to the workspace of the holder
+	ws := func(w corev1.WorkspaceKey) corev1.WorkspaceKey {
+		if w == "" {
+			return corev1.Workspace(holder)
+		}
+		return w
+	}
+
+	switch r := ref.(type) {
+	case corev1.AppEnvRef:
+		if r.AppEnvObjectName() == "" {
+			return ""
+		}
+		return nodeID("AppEnv", ws(r.Workspace), r.AppEnvObjectName(), "")
+	case corev1.ClusterRef:
+		if r.Name == "" {
+			return ""
+		}
+		return nodeID("Cluster", ws(r.Workspace), r.Name, "")
+	case corev1.CloudAccessConfigRef:
+		if r.Name == "" {
+			return ""
+		}
+		return nodeID("CloudAccessConfig", ws(r.Workspace), r.Name, "")
+	case corev1.Ownership:
+		if r.Kind == "" || r.Name == "" {
+			return ""
+		}
+		return nodeID(r.Kind, r.Workspace(), r.Name, "")
+	}
+
+	return ""
+}
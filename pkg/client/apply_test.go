@@ -0,0 +1,179 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appv2beta1 "github.com/appvia/wfclient/pkg/apis/app/v2beta1"
+	corev1 "github.com/appvia/wfclient/pkg/apis/core/v1alpha1"
+	"github.com/appvia/wfclient/pkg/client/config"
+)
+
+func newTestAppDefinition(name string, version corev1.ObjectVersion) *appv2beta1.AppDefinition {
+	return &appv2beta1.AppDefinition{
+		TypeMeta:   metav1.TypeMeta{APIVersion: appv2beta1.GroupVersion.String(), Kind: appv2beta1.AppDefinitionKind},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       appv2beta1.AppDefinitionSpec{Version: version},
+	}
+}
+
+func newTestAppEnv(app, env string) *appv2beta1.AppEnv {
+	return &appv2beta1.AppEnv{
+		TypeMeta:   metav1.TypeMeta{APIVersion: appv2beta1.GroupVersion.String(), Kind: appv2beta1.AppEnvKind},
+		ObjectMeta: metav1.ObjectMeta{Name: app + "-" + env, Namespace: corev1.WorkspaceKey("demo").Namespace()},
+		Spec:       appv2beta1.AppEnvSpec{Application: app, Name: env},
+	}
+}
+
+func newTestAppDeploymentJob(name string, appEnv corev1.AppEnvRef, plan corev1.PlanRef) *appv2beta1.AppDeploymentJob {
+	return &appv2beta1.AppDeploymentJob{
+		TypeMeta:   metav1.TypeMeta{APIVersion: appv2beta1.GroupVersion.String(), Kind: appv2beta1.AppDeploymentJobKind},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: corev1.WorkspaceKey("demo").Namespace()},
+		Spec: appv2beta1.AppDeploymentJobSpec{
+			AppEnvRef: appEnv,
+			AppDefinition: appv2beta1.AppDefinitionData{
+				Components: appv2beta1.ComponentDefinitions{
+					"web": {Component: appv2beta1.Component{Plan: plan}},
+				},
+			},
+		},
+	}
+}
+
+func applyOrder(objects []Object) []string {
+	var names []string
+	for _, o := range objects {
+		names = append(names, applyNodeID(o))
+	}
+	return names
+}
+
+func TestPlanApplyOrdersReferencesFirst(t *testing.T) {
+	job := newTestAppDeploymentJob("deploy",
+		corev1.AppEnvRef{App: "shop", EnvName: "dev"},
+		corev1.PlanRef{Name: "shop", Version: "1.0.0"})
+	env := newTestAppEnv("shop", "dev")
+	def := newTestAppDefinition("shop", "1.0.0")
+
+	plan, err := PlanApply([]Object{job, env, def})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"AppDefinition/shop@1.0.0",
+		"AppEnv/demo/shop-dev",
+		"AppDeploymentJob/demo/deploy",
+	}, applyOrder(plan))
+}
+
+func TestPlanApplyOrdersAppDefinitionVersionsBeforeAppEnvs(t *testing.T) {
+	env := newTestAppEnv("shop", "dev")
+	other := newTestAppEnv("cart", "dev")
+	v1 := newTestAppDefinition("shop", "1.0.0")
+	v2 := newTestAppDefinition("shop", "2.0.0")
+
+	plan, err := PlanApply([]Object{env, other, v1, v2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"AppEnv/demo/cart-dev",
+		"AppDefinition/shop@1.0.0",
+		"AppDefinition/shop@2.0.0",
+		"AppEnv/demo/shop-dev",
+	}, applyOrder(plan))
+}
+
+func TestPlanApplyKeepsOrderOfUnrelatedObjects(t *testing.T) {
+	a := newTestAppEnv("shop", "prod")
+	b := newTestAppEnv("shop", "dev")
+	c := newTestAppDefinition("cart", "1.0.0")
+
+	plan, err := PlanApply([]Object{a, b, c})
+	require.NoError(t, err)
+	assert.Equal(t, applyOrder([]Object{a, b, c}), applyOrder(plan))
+}
+
+func TestPlanApplyIgnoresReferencesOutsideSet(t *testing.T) {
+	job := newTestAppDeploymentJob("deploy",
+		corev1.AppEnvRef{Workspace: "other", App: "shop", EnvName: "dev"},
+		corev1.PlanRef{Name: "shop", Version: "2.0.0"})
+	env := newTestAppEnv("shop", "dev")
+
+	plan, err := PlanApply([]Object{job, env})
+	require.NoError(t, err)
+	assert.Equal(t, applyOrder([]Object{job, env}), applyOrder(plan))
+}
+
+func TestPlanApplyDuplicate(t *testing.T) {
+	_, err := PlanApply([]Object{newTestAppEnv("shop", "dev"), newTestAppEnv("shop", "dev")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "more than once")
+}
+
+func TestPlanApplyCycle(t *testing.T) {
+	a := newTestAppDeploymentJob("a", corev1.AppEnvRef{}, corev1.PlanRef{})
+	env := newTestAppEnv("shop", "dev")
+	env.Spec.ClusterRef = corev1.Ownership{Kind: appv2beta1.AppDeploymentJobKind, Namespace: env.Namespace, Name: "a"}
+	a.Spec.AppEnvRef = corev1.AppEnvRef{App: "shop", EnvName: "dev"}
+
+	_, err := PlanApply([]Object{a, env})
+	require.ErrorIs(t, err, ErrApplyCycle)
+}
+
+func TestApplyAll(t *testing.T) {
+	cfg := config.NewEmpty()
+	cfg.CreateProfile("test", "http://wayfinder.test")
+	cfg.AddAuthInfo("test", &config.AuthInfo{Token: new(string)})
+	cfg.CurrentProfile = "test"
+
+	var requests []string
+	wf, err := NewWFClient(cfg, UseRequestDo(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req.Method+" "+req.URL.Path+"?"+req.URL.RawQuery)
+		header := http.Header{}
+		header.Add("warning", `{"warningType":"General","name":"spec","message":"check me"}`)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader("{}")),
+			Request:    req,
+		}, nil
+	}))
+	require.NoError(t, err)
+
+	job := newTestAppDeploymentJob("deploy", corev1.AppEnvRef{App: "shop", EnvName: "dev"}, corev1.PlanRef{})
+	env := newTestAppEnv("shop", "dev")
+
+	result, err := ApplyAll(context.Background(), wf, []Object{job, env}, ApplyOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"PUT /resources/app.appvia.io/v2beta1/workspaces/demo/appenvs/shop-dev?apply=true&dryRun=All",
+		"PUT /resources/app.appvia.io/v2beta1/workspaces/demo/appdeploymentjobs/deploy?apply=true&dryRun=All",
+	}, requests)
+	assert.Len(t, result.Applied, 2)
+	assert.Len(t, result.Warnings, 2)
+}
+
+func TestApplyAllPruneRequiresSelector(t *testing.T) {
+	_, err := ApplyAll(context.Background(), nil, nil, ApplyOptions{Prune: true})
+	require.Error(t, err)
+}
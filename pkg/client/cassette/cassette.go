@@ -0,0 +1,145 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package cassette records the HTTP interactions of the client to YAML cassettes and replays them,
+// so tests can exercise the client against recorded API responses without a live server
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/appvia/wfclient/pkg/common"
+)
+
+// Version is the version of the cassette format
+const Version = 1
+
+// ErrUnmatchedRequest is returned by the replayer for requests which are not in the cassette
+var ErrUnmatchedRequest = errors.New("request not found in cassette")
+
+// Cassette is a recorded set of interactions
+type Cassette struct {
+	// Version is the version of the cassette format
+	Version int `yaml:"version"`
+	// Interactions are the recorded requests and responses, in the order they were made
+	Interactions []*Interaction `yaml:"interactions"`
+}
+
+// Interaction is a recorded request and its response
+type Interaction struct {
+	Request  Request  `yaml:"request"`
+	Response Response `yaml:"response"`
+}
+
+// Request is a recorded request
+type Request struct {
+	// Method is the http method of the request
+	Method string `yaml:"method"`
+	// URI is the path and query of the request
+	URI string `yaml:"uri"`
+	// BodyHash is the hash of the request body, used to match requests on replay
+	BodyHash string `yaml:"bodyHash,omitempty"`
+	// Headers are the request headers, with credentials redacted
+	Headers http.Header `yaml:"headers,omitempty"`
+	// Body is the request body, with secrets redacted
+	Body string `yaml:"body,omitempty"`
+}
+
+// Response is a recorded response
+type Response struct {
+	// StatusCode is the http status code
+	StatusCode int `yaml:"status"`
+	// Headers are the response headers, with credentials redacted
+	Headers http.Header `yaml:"headers,omitempty"`
+	// Body is the response body, with secrets redacted
+	Body string `yaml:"body,omitempty"`
+}
+
+// Key identifies the request for matching on replay
+func (r Request) Key() string {
+	return fmt.Sprintf("%s %s %s", r.Method, r.URI, r.BodyHash)
+}
+
+// Load reads a cassette from the file
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cassette := &Cassette{}
+	if err := yaml.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("failed to decode cassette %s: %w", path, err)
+	}
+	if cassette.Version != Version {
+		return nil, fmt.Errorf("unsupported cassette version %d in %s", cassette.Version, path)
+	}
+
+	return cassette, nil
+}
+
+// Save writes the cassette to the file
+func (c *Cassette) Save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+// hashBody returns the hash used to match request bodies. The redacted body is hashed, as a hash
+// of a low entropy secret such as a password could be brute forced from the cassette.
+func hashBody(redacted string) string {
+	if redacted == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(redacted))
+
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// newRequest records the request, restoring the body so it can still be sent
+func newRequest(req *http.Request) (Request, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return Request{}, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	redacted := common.RedactBody(body)
+
+	return Request{
+		Method:   req.Method,
+		URI:      req.URL.RequestURI(),
+		BodyHash: hashBody(redacted),
+		Headers:  common.RedactHeaders(req.Header),
+		Body:     redacted,
+	}, nil
+}
@@ -0,0 +1,179 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cassette_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/appvia/wfclient/pkg/client"
+	"github.com/appvia/wfclient/pkg/client/cassette"
+	"github.com/appvia/wfclient/pkg/client/config"
+)
+
+type testThing struct {
+	Name  string `json:"name"`
+	Token string `json:"token,omitempty"`
+}
+
+func newTestClient(t *testing.T, endpoint string, options ...client.OptionFunc) client.Interface {
+	cfg := config.NewEmpty()
+	cfg.CreateProfile("test", endpoint)
+	cfg.CurrentProfile = "test"
+	token := "api-secret"
+	cfg.AddAuthInfo("test", &config.AuthInfo{Token: &token})
+
+	c, err := client.New(cfg, options...)
+	require.NoError(t, err)
+
+	return c
+}
+
+// exercise makes a create, a rate limited get which is retried, and a followed stream
+func exercise(t *testing.T, c client.Interface) (*testThing, *testThing, string) {
+	created := &testThing{}
+	require.NoError(t, c.Request().Context(context.Background()).Endpoint("/things").
+		Payload(&testThing{Name: "one"}).Result(created).Create().Error())
+
+	fetched := &testThing{}
+	require.NoError(t, c.Request().Context(context.Background()).Endpoint("/things/one").
+		Result(fetched).Get().Error())
+
+	stream, err := c.Request().Context(context.Background()).Endpoint("/things/one/logs").
+		Follow(true).Get().Do()
+	require.NoError(t, err)
+	logs, err := io.ReadAll(stream.Body())
+	require.NoError(t, err)
+
+	return created, fetched, string(logs)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	var limited int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer api-secret", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/api/v2/things":
+			thing := &testThing{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(thing))
+			thing.Token = "created-secret"
+			_ = json.NewEncoder(w).Encode(thing)
+		case "/api/v2/things/one":
+			if atomic.AddInt32(&limited, 1) == 1 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(&testThing{Name: "one"})
+		case "/api/v2/things/one/logs":
+			_, _ = w.Write([]byte("line one\n"))
+			w.(http.Flusher).Flush()
+			_, _ = w.Write([]byte("line two\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+	recorder := cassette.NewRecorder(path, nil)
+
+	created, fetched, logs := exercise(t, newTestClient(t, server.URL, client.UseTransportWrapper(recorder.Wrap)))
+	server.Close()
+	assert.Equal(t, "created-secret", created.Token)
+	assert.Equal(t, "one", fetched.Name)
+	assert.Equal(t, "line one\nline two\n", logs)
+	require.NoError(t, recorder.Save())
+
+	recorded, err := cassette.Load(path)
+	require.NoError(t, err)
+	require.Len(t, recorded.Interactions, 4)
+	assert.Equal(t, http.StatusTooManyRequests, recorded.Interactions[1].Response.StatusCode)
+	assert.Equal(t, http.StatusOK, recorded.Interactions[2].Response.StatusCode)
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "api-secret")
+	assert.NotContains(t, string(raw), "created-secret")
+
+	t.Run("replay", func(t *testing.T) {
+		replayer, err := cassette.NewReplayer(path)
+		require.NoError(t, err)
+
+		created, fetched, logs := exercise(t, newTestClient(t, server.URL, client.UseRequestDo(replayer.Do)))
+		assert.Equal(t, "one", created.Name)
+		assert.Equal(t, "[redacted]", created.Token)
+		assert.Equal(t, "one", fetched.Name)
+		assert.Equal(t, "line one\nline two\n", logs)
+		assert.Empty(t, replayer.Unused())
+	})
+
+	t.Run("unknown request", func(t *testing.T) {
+		replayer, err := cassette.NewReplayer(path)
+		require.NoError(t, err)
+		c := newTestClient(t, server.URL, client.UseTransportWrapper(replayer.Wrap))
+
+		err = c.Request().Context(context.Background()).Endpoint("/things").
+			Payload(&testThing{Name: "two"}).Create().Error()
+		require.Error(t, err)
+		assert.ErrorIs(t, err, cassette.ErrUnmatchedRequest)
+		assert.Len(t, replayer.Unused(), 4)
+	})
+}
+
+func TestRecordedBodyHashOmitsSecrets(t *testing.T) {
+	body := `{"username":"admin","password":"hunter2"}`
+	recorder := cassette.NewRecorder(filepath.Join(t.TempDir(), "cassette.yaml"), roundTripper(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+	}))
+
+	req, err := http.NewRequest(http.MethodPost, "http://wayfinder.test/api/v2/login/local", strings.NewReader(body))
+	require.NoError(t, err)
+	resp, err := recorder.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	sum := sha256.Sum256([]byte(body))
+	recorded := recorder.Cassette().Interactions[0].Request
+	assert.NotContains(t, recorded.BodyHash, hex.EncodeToString(sum[:]))
+	assert.NotContains(t, recorded.Body, "hunter2")
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestLoadRejectsUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("version: 9\n"), 0600))
+
+	_, err := cassette.Load(path)
+	assert.Error(t, err)
+}
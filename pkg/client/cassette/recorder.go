@@ -0,0 +1,132 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cassette
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/appvia/wfclient/pkg/common"
+	"github.com/appvia/wfclient/pkg/utils/httputils"
+)
+
+// Recorder is a http transport which records the interactions it passes to the inner transport.
+// The recorder can be used via client.UseRequestDo with Do, or wrap the client's transport with Wrap,
+// which retains the TLS and proxy settings of the profile.
+type Recorder struct {
+	// mu guards the recorded interactions
+	mu sync.Mutex
+	// path is the file the cassette is saved to
+	path string
+	// next is the transport used to perform the requests
+	next http.RoundTripper
+	// cassette holds the recorded interactions
+	cassette *Cassette
+}
+
+// NewRecorder returns a recorder saving to the path, using the transport to perform requests. If no
+// transport is provided http.DefaultTransport is used.
+func NewRecorder(path string, next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &Recorder{
+		path:     path,
+		next:     next,
+		cassette: &Cassette{Version: Version},
+	}
+}
+
+// Do performs and records the request
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	return r.record(r.next, req)
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r.record(r.next, req)
+}
+
+// Wrap returns a transport which records the requests performed by the transport provided
+func (r *Recorder) Wrap(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return r.record(next, req)
+	})
+}
+
+// Cassette returns a copy of the interactions recorded so far
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cassette := &Cassette{Version: r.cassette.Version}
+	for _, interaction := range r.cassette.Interactions {
+		copied := *interaction
+		cassette.Interactions = append(cassette.Interactions, &copied)
+	}
+
+	return cassette
+}
+
+// Save writes the recorded interactions to the cassette file
+func (r *Recorder) Save() error {
+	return r.Cassette().Save(r.path)
+}
+
+// record performs the request and records the interaction. The response body is recorded as it is
+// read by the caller, so streamed responses are captured without being buffered up front.
+func (r *Recorder) record(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+	request, err := newRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := &Interaction{
+		Request: request,
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Headers:    common.RedactHeaders(resp.Header),
+		},
+	}
+
+	// @step: add the interaction now so they are kept in the order the requests were made
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	resp.Body = httputils.CaptureBody(resp.Body, func(body []byte) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		interaction.Response.Body = common.RedactBody(body)
+	})
+
+	return resp, nil
+}
+
+// roundTripperFunc adapts a function to a http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
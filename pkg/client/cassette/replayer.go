@@ -0,0 +1,115 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cassette
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Replayer is a http transport which serves the responses recorded in a cassette, and fails any
+// request which was not recorded. Repeated requests are served in the order they were recorded, so
+// retried and polled requests replay the same sequence of responses.
+type Replayer struct {
+	// mu guards the interactions left to replay
+	mu sync.Mutex
+	// cassette holds the recorded interactions
+	cassette *Cassette
+	// used indicates which interactions have been served
+	used []bool
+}
+
+// NewReplayer returns a replayer serving the cassette in the file
+func NewReplayer(path string) (*Replayer, error) {
+	cassette, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCassetteReplayer(cassette), nil
+}
+
+// NewCassetteReplayer returns a replayer serving the cassette
+func NewCassetteReplayer(cassette *Cassette) *Replayer {
+	return &Replayer{
+		cassette: cassette,
+		used:     make([]bool, len(cassette.Interactions)),
+	}
+}
+
+// Do serves the recorded response for the request
+func (r *Replayer) Do(req *http.Request) (*http.Response, error) {
+	return r.RoundTrip(req)
+}
+
+// Wrap returns the replayer in place of the transport, for use with client.UseTransportWrapper
+func (r *Replayer) Wrap(_ http.RoundTripper) http.RoundTripper {
+	return r
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	request, err := newRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	key := request.Key()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || interaction.Request.Key() != key {
+			continue
+		}
+		r.used[i] = true
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Headers.Clone(),
+			Body:          io.NopCloser(bytes.NewReader([]byte(interaction.Response.Body))),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrUnmatchedRequest, request.Method, request.URI)
+}
+
+// Unused returns the recorded requests which have not been replayed, so tests can check the
+// client made every request expected of it
+func (r *Replayer) Unused() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var list []string
+	for i, interaction := range r.cassette.Interactions {
+		if !r.used[i] {
+			list = append(list, strings.TrimSpace(interaction.Request.Method+" "+interaction.Request.URI))
+		}
+	}
+
+	return list
+}
@@ -18,6 +18,7 @@ package client
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -35,12 +36,17 @@ var (
 
 // cc provides a wrapper around th config
 type cc struct {
-	cfg            *config.Config
-	handler        UpdateHandlerFunc
-	warningHandler WarningHandler
-	profile        string
-	apiClient      func(cfg *config.Config) RestInterface
-	requestDo      RequestDo
+	cfg              *config.Config
+	handler          UpdateHandlerFunc
+	warningHandler   WarningHandler
+	profile          string
+	apiClient        func(cfg *config.Config) RestInterface
+	requestDo        RequestDo
+	transportWrapper func(http.RoundTripper) http.RoundTripper
+	httpClients      *httpClientCache
+	har              *HARRecorder
+	expiryWarning    time.Duration
+	expiryHandler    TokenExpiryHandler
 }
 
 // NewClient returns a new client for the provided config, without silly nil checks for nicer usage.
@@ -55,6 +61,11 @@ func NewClient(cfg *config.Config, options ...OptionFunc) Interface {
 
 // New creates and returns an API client
 func New(cfg *config.Config, options ...OptionFunc) (Interface, error) {
+	return NewSessionClient(cfg, options...)
+}
+
+// NewSessionClient creates and returns an API client which can log in and out of the server
+func NewSessionClient(cfg *config.Config, options ...OptionFunc) (SessionClient, error) {
 	if cfg == nil {
 		return nil, errors.New("no client configuration")
 	}
@@ -65,6 +76,19 @@ func New(cfg *config.Config, options ...OptionFunc) (Interface, error) {
 	for _, fn := range options {
 		fn(c)
 	}
+	c.httpClients = newHTTPClientCache(c.transportWrapper)
+	if c.har == nil {
+		har, err := harRecorderFromEnv()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open HTTP archive: %w", err)
+		}
+		c.har = har
+	}
+	if c.expiryHandler != nil {
+		for _, warning := range CheckTokenExpiry(cfg, c.expiryWarning) {
+			c.expiryHandler(warning)
+		}
+	}
 	if c.apiClient == nil {
 		c.apiClient = func(cfg *config.Config) RestInterface {
 			return &apiClient{
@@ -75,6 +99,8 @@ func New(cfg *config.Config, options ...OptionFunc) (Interface, error) {
 				profile:         c.CurrentProfile(),
 				warningHandler:  c.warningHandler,
 				customRequestDo: c.requestDo,
+				httpClients:     c.httpClients,
+				har:             c.har,
 			}
 		}
 	}
@@ -82,6 +108,16 @@ func New(cfg *config.Config, options ...OptionFunc) (Interface, error) {
 	return c, nil
 }
 
+// Close closes the idle connections of the client and finishes any HTTP archive being recorded
+func (c *cc) Close() error {
+	c.httpClients.close()
+	if c.har != nil {
+		return c.har.Close()
+	}
+
+	return nil
+}
+
 // Config return a copy of the client configuration
 func (c *cc) Config() *config.Config {
 	return c.cfg
@@ -100,7 +136,7 @@ func (c *cc) CurrentProfile() string {
 		return c.profile
 	}
 
-	return c.cfg.CurrentProfile
+	return c.cfg.GetCurrentProfile()
 }
 
 // Request creates a request instance
@@ -122,8 +158,7 @@ func (c *cc) RefreshIdentity() error {
 	case auth.Identity.IsExchangeToken():
 		common.LogWithoutContext().Debug("Refresh access token via access token exchange")
 
-		ttl := 30 * time.Minute
-		token, err := ExchangeAccessToken(c, []byte(auth.Identity.RefreshToken), ttl)
+		token, err := ExchangeAccessToken(c, []byte(auth.Identity.RefreshToken), exchangeTokenTTL)
 		if err != nil {
 			common.LogWithoutContext().WithError(err).Error("trying to exchange access token")
 
@@ -183,6 +218,7 @@ func (c *cc) CheckServer(force, saveProfile bool) error {
 
 // handleConfigurationUpdate is called when the configuration has been updated
 func (c *cc) handleConfigurationUpdate() error {
+	c.httpClients.retain(c.cfg.Servers)
 	if c.handler == nil {
 		return nil
 	}
@@ -17,8 +17,7 @@
 package config
 
 import (
-	"crypto/tls"
-	"encoding/pem"
+	"bytes"
 	"errors"
 	"io"
 	"net/url"
@@ -27,17 +26,42 @@ import (
 	"gopkg.in/yaml.v2"
 
 	"github.com/appvia/wfclient/pkg/authtypes"
-	"github.com/appvia/wfclient/pkg/common"
+	"github.com/appvia/wfclient/pkg/utils/validation"
 	"github.com/appvia/wfclient/pkg/version"
 )
 
-// New creates a configuration
+// New creates a configuration, migrating it to the current schema version if required
 func New(reader io.Reader) (*Config, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, io.EOF
+	}
+
+	migrated, changed, err := migrateConfigData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	conf, err := decodeConfig(migrated)
+	if err != nil {
+		return nil, err
+	}
+	conf.migrated = changed
+
+	return conf, nil
+}
+
+// decodeConfig decodes a configuration which has already been migrated
+func decodeConfig(data []byte) (*Config, error) {
 	conf := &Config{}
 
-	if err := yaml.NewDecoder(reader).Decode(conf); err != nil {
+	if err := yaml.Unmarshal(data, conf); err != nil {
 		return nil, err
 	}
+	conf.markPersisted()
 
 	return conf, nil
 }
@@ -49,12 +73,20 @@ func NewEmpty() *Config {
 		Profiles:  make(map[string]*Profile),
 		Servers:   make(map[string]*Server),
 		Version:   version.Release,
+
+		SchemaVersion: CurrentSchemaVersion(),
 	}
 }
 
-// IsValid checks if the configuration is valid
+// IsValid checks if the configuration is valid, returning a validation error if any problems
+// other than warnings are found. Use Validate to retrieve the warnings as well.
 func (c *Config) IsValid() error {
-	return nil
+	verr := c.Validate()
+	if verr == nil || len(verr.GetNonWarnings()) == 0 {
+		return nil
+	}
+
+	return &validation.Error{Code: verr.Code, Message: verr.Message, FieldErrors: verr.GetNonWarnings()}
 }
 
 // IsAccessToken returns true if the current profile is for an access token user
@@ -79,50 +111,22 @@ func (c *Config) NewProfileWithAuth(name, endpoint string, auth *AuthInfo) error
 	return nil
 }
 
-// CreateProfile is used to create a profile
+// CreateProfile is used to create a profile. The certificate of a local development server is
+// trusted automatically, use CreateProfileWithTrust to trust and pin the certificate of any other
+// server.
 func (c *Config) CreateProfile(name, endpoint string) {
-	var ca []byte
+	var ca string
 
 	u, _ := url.Parse(endpoint)
 	if u != nil && u.Scheme == "https" && u.Hostname() == "localhost" {
-		ca = c.getUntrustedCA(strings.TrimPrefix(endpoint, "https://"))
+		ca = localhostCA(endpoint)
 	}
 
 	c.AddProfile(name, &Profile{
 		Server:   name,
 		AuthInfo: name,
 	})
-	c.AddServer(name, &Server{Endpoint: endpoint, CACertificate: string(ca)})
-}
-
-func (c *Config) getUntrustedCA(url string) (ca []byte) {
-	conn, err := tls.Dial("tcp", url, &tls.Config{
-		InsecureSkipVerify: true,
-	})
-	if err != nil {
-		common.LogWithoutContext().Debugf("failed to connect to %s: %s", url, err.Error())
-		return nil
-	}
-
-	if err := conn.Handshake(); err != nil {
-		common.LogWithoutContext().Debugf("SSL handshake failed with %s: %s", url, err.Error())
-		return nil
-	}
-
-	l := len(conn.ConnectionState().PeerCertificates)
-	caCert := conn.ConnectionState().PeerCertificates[0]
-	cert := conn.ConnectionState().PeerCertificates[l-1]
-	for _, domain := range cert.DNSNames {
-		if domain == "localhost" {
-			block := &pem.Block{
-				Type:  "CERTIFICATE",
-				Bytes: caCert.Raw,
-			}
-			return pem.EncodeToMemory(block)
-		}
-	}
-
-	return nil
+	c.AddServer(name, &Server{Endpoint: endpoint, CACertificate: ca})
 }
 
 // ListProfiles returns a list of profile names
@@ -139,6 +143,11 @@ func (c *Config) ListProfiles() []string {
 	return list
 }
 
+// ListAuthInfos returns the names of the users, sorted
+func (c *Config) ListAuthInfos() []string {
+	return sortedKeys(c.AuthInfos)
+}
+
 // GetProfile returns the profile
 func (c *Config) GetProfile(name string) *Profile {
 	if !c.HasProfile(name) {
@@ -148,11 +157,15 @@ func (c *Config) GetProfile(name string) *Profile {
 	return c.Profiles[name]
 }
 
-// GetProfileAuthMethod returns the method of authentication for a profile
+// GetProfileAuthMethod returns the method of authentication for a profile, taking into account a
+// token file override
 func (c *Config) GetProfileAuthMethod(name string) string {
 	if !c.HasProfile(name) {
 		return ""
 	}
+	if c.overrides.TokenFile != "" {
+		return "tokenFile"
+	}
 	if !c.HasAuthInfo(c.Profiles[name].AuthInfo) {
 		return ""
 	}
@@ -162,6 +175,10 @@ func (c *Config) GetProfileAuthMethod(name string) string {
 		return "token"
 	case auth.Identity != nil:
 		return "idtoken"
+	case auth.Exec != nil:
+		return "exec"
+	case auth.ServiceAccount != nil:
+		return "serviceAccount"
 	}
 
 	return "none"
@@ -210,7 +227,7 @@ func (c *Config) GetAuthInfo(name string) *AuthInfo {
 // HasAuth checks if we have auth enabled
 func (c *Config) HasAuth(name string) bool {
 	a := c.GetAuthInfo(name)
-	if a.Token != nil || a.Identity != nil {
+	if a.Token != nil || a.Identity != nil || a.Exec != nil || a.ServiceAccount != nil {
 		return true
 	}
 
@@ -247,6 +264,9 @@ func (c *Config) HasValidProfile(name string) error {
 	if name == "" {
 		return ErrNoProfileSelected
 	}
+	if c.Profiles[name] == nil {
+		return ErrNoProfile
+	}
 	if !c.HasServer(c.Profiles[name].Server) {
 		return ErrNoProfileEndpoint
 	}
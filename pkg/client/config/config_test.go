@@ -106,6 +106,17 @@ func TestAddProfile(t *testing.T) {
 }
 
 func TestIsValid(t *testing.T) {
-	c := newTestConfig(t)
+	c := NewEmpty()
+	c.CreateProfile("local", "http://127.0.0.1:10080")
+	c.AddAuthInfo("local", &AuthInfo{Token: new(string)})
+	*c.AuthInfos["local"].Token = "static"
+	c.CurrentProfile = "local"
 	assert.Nil(t, c.IsValid())
 }
+
+func TestIsValidNoAuth(t *testing.T) {
+	// the test configuration uses a legacy auth method which is no longer supported
+	err := newTestConfig(t).IsValid()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "profiles.local.user: user \"local\" has no authentication configured")
+}
@@ -0,0 +1,237 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// The environment variables which override the client configuration. Settings are resolved in the
+// following order of precedence, highest first:
+//
+//  1. explicit overrides made by the caller, such as a --profile flag (client.OverrideProfile)
+//  2. these environment variables
+//  3. the configuration files, in the order given in WAYFINDER_CONFIG
+//  4. the client defaults
+//
+// Where WAYFINDER_SERVER is set along with WAYFINDER_TOKEN or WAYFINDER_TOKEN_FILE, the
+// configuration files are not used at all and an ephemeral configuration is created instead.
+const (
+	// EnvWayfinderProfile overrides the current profile of a file-based configuration
+	EnvWayfinderProfile = "WAYFINDER_PROFILE"
+	// EnvWayfinderCACert is a PEM-encoded CA bundle to trust for the server
+	EnvWayfinderCACert = "WAYFINDER_CA_CERT"
+	// EnvWayfinderCACertFile is the path to a PEM-encoded CA bundle to trust for the server
+	EnvWayfinderCACertFile = "WAYFINDER_CA_CERT_FILE"
+	// EnvWayfinderInsecureSkipVerify disables verification of the server certificate
+	EnvWayfinderInsecureSkipVerify = "WAYFINDER_INSECURE_SKIP_VERIFY"
+	// EnvWayfinderProxy is the URL of a proxy to use to reach the server
+	EnvWayfinderProxy = "WAYFINDER_PROXY"
+	// EnvWayfinderTokenFile is the path to a file holding the token, which is re-read when it changes
+	EnvWayfinderTokenFile = "WAYFINDER_TOKEN_FILE"
+	// EnvWayfinderConfigReadOnly prevents the client from writing to the configuration files
+	EnvWayfinderConfigReadOnly = "WAYFINDER_CONFIG_READONLY"
+)
+
+// Overrides are the settings which take precedence over the configuration files
+type Overrides struct {
+	// Profile overrides the current profile
+	Profile string
+	// CACertificate overrides the CA bundle of the server
+	CACertificate string
+	// CACertificateSource is the environment variable the CA bundle was taken from
+	CACertificateSource string
+	// InsecureSkipTLSVerify overrides verification of the server certificate
+	InsecureSkipTLSVerify *bool
+	// ProxyURL overrides the proxy used to reach the server
+	ProxyURL string
+	// TokenFile overrides the credentials of the profile with a token read from a file
+	TokenFile string
+}
+
+// IsEmpty checks if no overrides are set
+func (o Overrides) IsEmpty() bool {
+	return o == Overrides{}
+}
+
+// OverridesFromEnv returns the overrides set in the environment
+func OverridesFromEnv() (Overrides, error) {
+	o := Overrides{
+		Profile:   os.Getenv(EnvWayfinderProfile),
+		ProxyURL:  os.Getenv(EnvWayfinderProxy),
+		TokenFile: os.Getenv(EnvWayfinderTokenFile),
+	}
+
+	switch {
+	case os.Getenv(EnvWayfinderCACert) != "":
+		o.CACertificate = os.Getenv(EnvWayfinderCACert)
+		o.CACertificateSource = EnvWayfinderCACert
+	case os.Getenv(EnvWayfinderCACertFile) != "":
+		data, err := os.ReadFile(os.Getenv(EnvWayfinderCACertFile))
+		if err != nil {
+			return Overrides{}, fmt.Errorf("failed to read %s: %w", EnvWayfinderCACertFile, err)
+		}
+		o.CACertificate = string(data)
+		o.CACertificateSource = EnvWayfinderCACertFile
+	}
+
+	if v := os.Getenv(EnvWayfinderInsecureSkipVerify); v != "" {
+		insecure, err := strconv.ParseBool(v)
+		if err != nil {
+			return Overrides{}, fmt.Errorf("invalid value for %s: %w", EnvWayfinderInsecureSkipVerify, err)
+		}
+		o.InsecureSkipTLSVerify = &insecure
+	}
+
+	return o, nil
+}
+
+// IsConfigReadOnly checks if the client has been asked not to write to the configuration files
+func IsConfigReadOnly() bool {
+	readonly, _ := strconv.ParseBool(os.Getenv(EnvWayfinderConfigReadOnly))
+	return readonly
+}
+
+// SetOverrides sets the overrides which take precedence over the configuration. They are applied
+// when the effective settings are retrieved, and are never written to the configuration files.
+func (c *Config) SetOverrides(o Overrides) {
+	c.overrides = o
+}
+
+// GetOverrides returns the overrides set on the configuration
+func (c *Config) GetOverrides() Overrides {
+	return c.overrides
+}
+
+// GetCurrentProfile returns the effective current profile, taking any override into account
+func (c *Config) GetCurrentProfile() string {
+	if c.overrides.Profile != "" {
+		return c.overrides.Profile
+	}
+
+	return c.CurrentProfile
+}
+
+// GetEffectiveServer returns a copy of the server for the profile with any overrides applied, or
+// nil if the profile or server does not exist
+func (c *Config) GetEffectiveServer(profile string) *Server {
+	p := c.Profiles[profile]
+	if p == nil || c.Servers[p.Server] == nil {
+		return nil
+	}
+
+	server := *c.Servers[p.Server]
+	if c.overrides.CACertificate != "" {
+		server.CACertificate = c.overrides.CACertificate
+	}
+	if c.overrides.InsecureSkipTLSVerify != nil {
+		server.InsecureSkipTLSVerify = *c.overrides.InsecureSkipTLSVerify
+	}
+	if c.overrides.ProxyURL != "" {
+		server.ProxyURL = c.overrides.ProxyURL
+	}
+
+	return &server
+}
+
+// EffectiveValue describes a setting in use and where its value came from
+type EffectiveValue struct {
+	// Name is the name of the setting
+	Name string `json:"name" yaml:"name"`
+	// Value is the value in use, with secrets omitted
+	Value string `json:"value" yaml:"value"`
+	// Source is where the value came from: an environment variable, a configuration file, or
+	// "default"
+	Source string `json:"source" yaml:"source"`
+}
+
+// GetEffectiveValues reports the settings in use for the profile (or the current profile if
+// empty) and the source of each of them
+func (c *Config) GetEffectiveValues(profile string) []EffectiveValue {
+	var values []EffectiveValue
+	// values either come from a file, or the environment for an ephemeral configuration
+	fileSource := func(path, env string) string {
+		switch {
+		case path != "":
+			return path
+		case IsEphemeralConfig():
+			return env
+		}
+		return "default"
+	}
+
+	// @step: the profile itself
+	if profile == "" {
+		profile = c.GetCurrentProfile()
+		if c.overrides.Profile != "" {
+			values = append(values, EffectiveValue{Name: "profile", Value: profile, Source: EnvWayfinderProfile})
+		} else {
+			values = append(values, EffectiveValue{Name: "profile", Value: profile, Source: fileSource(c.origins[originKey("current-profile", "")], "default")})
+		}
+	} else {
+		values = append(values, EffectiveValue{Name: "profile", Value: profile, Source: "caller"})
+	}
+
+	origin := c.Origin(profile)
+	if p := c.Profiles[profile]; p != nil && p.Workspace != "" {
+		values = append(values, EffectiveValue{Name: "workspace", Value: p.Workspace, Source: fileSource(origin.Profile, EnvWayfinderWorkspace)})
+	}
+
+	// @step: the server settings
+	if server := c.GetEffectiveServer(profile); server != nil {
+		values = append(values, EffectiveValue{Name: "server", Value: server.Endpoint, Source: fileSource(origin.Server, EnvWayfinderServer)})
+
+		switch {
+		case c.overrides.CACertificate != "":
+			values = append(values, EffectiveValue{Name: "caCertificate", Value: "set", Source: c.overrides.CACertificateSource})
+		case server.CACertificate != "":
+			values = append(values, EffectiveValue{Name: "caCertificate", Value: "set", Source: fileSource(origin.Server, "default")})
+		}
+		switch {
+		case c.overrides.InsecureSkipTLSVerify != nil:
+			values = append(values, EffectiveValue{Name: "insecureSkipTLSVerify", Value: strconv.FormatBool(server.InsecureSkipTLSVerify), Source: EnvWayfinderInsecureSkipVerify})
+		case server.InsecureSkipTLSVerify:
+			values = append(values, EffectiveValue{Name: "insecureSkipTLSVerify", Value: "true", Source: fileSource(origin.Server, "default")})
+		}
+		switch {
+		case c.overrides.ProxyURL != "":
+			values = append(values, EffectiveValue{Name: "proxy", Value: server.ProxyURL, Source: EnvWayfinderProxy})
+		case server.ProxyURL != "":
+			values = append(values, EffectiveValue{Name: "proxy", Value: server.ProxyURL, Source: fileSource(origin.Server, "default")})
+		}
+	}
+
+	// @step: the credentials
+	switch {
+	case c.overrides.TokenFile != "":
+		values = append(values, EffectiveValue{Name: "auth", Value: "tokenFile " + c.overrides.TokenFile, Source: EnvWayfinderTokenFile})
+	case c.HasAuth(profile):
+		env := EnvWayfinderToken
+		if os.Getenv(EnvWayfinderToken) == "" {
+			env = EnvWayfinderTokenFile
+		}
+		values = append(values, EffectiveValue{Name: "auth", Value: c.GetProfileAuthMethod(profile), Source: fileSource(origin.AuthInfo, env)})
+	}
+
+	if IsConfigReadOnly() {
+		values = append(values, EffectiveValue{Name: "readonly", Value: "true", Source: EnvWayfinderConfigReadOnly})
+	}
+
+	return values
+}
@@ -0,0 +1,107 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverridesFromEnv(t *testing.T) {
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(ca, []byte("ca-from-file"), 0600))
+
+	t.Setenv(EnvWayfinderProfile, "prod")
+	t.Setenv(EnvWayfinderCACertFile, ca)
+	t.Setenv(EnvWayfinderInsecureSkipVerify, "true")
+	t.Setenv(EnvWayfinderProxy, "http://proxy.example.com:3128")
+
+	o, err := OverridesFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "prod", o.Profile)
+	assert.Equal(t, "ca-from-file", o.CACertificate)
+	assert.Equal(t, EnvWayfinderCACertFile, o.CACertificateSource)
+	require.NotNil(t, o.InsecureSkipTLSVerify)
+	assert.True(t, *o.InsecureSkipTLSVerify)
+	assert.Equal(t, "http://proxy.example.com:3128", o.ProxyURL)
+
+	// an inline certificate takes precedence over a file
+	t.Setenv(EnvWayfinderCACert, "inline-ca")
+	o, err = OverridesFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "inline-ca", o.CACertificate)
+
+	t.Setenv(EnvWayfinderInsecureSkipVerify, "maybe")
+	_, err = OverridesFromEnv()
+	require.Error(t, err)
+}
+
+func TestEffectiveServerAndValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	c := NewEmpty()
+	c.CreateProfile("dev", "https://dev.example.com")
+	c.CreateProfile("prod", "https://prod.example.com")
+	c.AddAuthInfo("prod", &AuthInfo{Token: new(string)})
+	c.CurrentProfile = "dev"
+	require.NoError(t, UpdateConfig(c, path))
+
+	c, err := Load(path)
+	require.NoError(t, err)
+	c.SetOverrides(Overrides{Profile: "prod", ProxyURL: "http://proxy.example.com"})
+
+	assert.Equal(t, "prod", c.GetCurrentProfile())
+	server := c.GetEffectiveServer("prod")
+	require.NotNil(t, server)
+	assert.Equal(t, "http://proxy.example.com", server.ProxyURL)
+	assert.Empty(t, c.Servers["prod"].ProxyURL, "overrides must not change the stored configuration")
+	assert.Nil(t, c.GetEffectiveServer("missing"))
+
+	assert.Equal(t, []EffectiveValue{
+		{Name: "profile", Value: "prod", Source: EnvWayfinderProfile},
+		{Name: "server", Value: "https://prod.example.com", Source: path},
+		{Name: "proxy", Value: "http://proxy.example.com", Source: EnvWayfinderProxy},
+		{Name: "auth", Value: "token", Source: path},
+	}, c.GetEffectiveValues(""))
+}
+
+func TestEphemeralConfigTokenFile(t *testing.T) {
+	t.Setenv(EnvWayfinderServer, "https://wayfinder.example.com")
+	t.Setenv(EnvWayfinderTokenFile, "/var/run/secrets/wayfinder/token")
+	t.Setenv(EnvWayfinderProfile, "ignored")
+
+	require.True(t, IsEphemeralConfig())
+	c, err := GetConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, "default", c.GetCurrentProfile())
+	assert.Equal(t, "/var/run/secrets/wayfinder/token", c.GetOverrides().TokenFile)
+	assert.Equal(t, "tokenFile", c.GetProfileAuthMethod("default"))
+	assert.Contains(t, c.GetEffectiveValues(""), EffectiveValue{Name: "server", Value: "https://wayfinder.example.com", Source: EnvWayfinderServer})
+}
+
+func TestConfigReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	t.Setenv(EnvWayfinderConfigReadOnly, "true")
+
+	require.NoError(t, UpdateConfig(NewEmpty(), path))
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -21,6 +21,8 @@ import "errors"
 var (
 	// ErrNoProfileSelected indicates the current profile is empty
 	ErrNoProfileSelected = errors.New("no profile selected")
+	// ErrNoProfile indicates the selected profile does not exist
+	ErrNoProfile = errors.New("profile does not exist")
 	// ErrNoProfileEndpoint indicates the profile does not have a endpoint
 	ErrNoProfileEndpoint = errors.New("profile does not have a server endpoint")
 	// ErrNoProfileAuth indicates the profile does not any auth configured
@@ -0,0 +1,427 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/pbkdf2"
+	"gopkg.in/yaml.v2"
+
+	"github.com/appvia/wfclient/pkg/utils/validation"
+)
+
+const (
+	// ProfileBundleAPIVersion is the version of the profile bundle format
+	ProfileBundleAPIVersion = "wayfinder.appvia.io/v1"
+	// ProfileBundleKind is the kind of a profile bundle document
+	ProfileBundleKind = "ProfileBundle"
+	// bundleCipher is the key derivation and cipher used to encrypt bundle credentials
+	bundleCipher = "pbkdf2-sha256+aes-256-gcm"
+	// bundleIterations is the number of PBKDF2 iterations used to derive the encryption key
+	bundleIterations = 600000
+	// bundleMinPassphrase is the minimum length of a passphrase used to encrypt credentials
+	bundleMinPassphrase = 8
+)
+
+var (
+	// ErrPassphraseRequired indicates a passphrase is needed to encrypt or decrypt credentials
+	ErrPassphraseRequired = errors.New("a passphrase is required to encrypt or decrypt credentials")
+	// ErrInvalidPassphrase indicates the credentials in a bundle could not be decrypted
+	ErrInvalidPassphrase = errors.New("invalid passphrase or corrupted credentials")
+)
+
+// ProfileBundle is a self-contained, portable export of a profile which can be shared and imported
+// into another configuration
+type ProfileBundle struct {
+	// APIVersion is the version of the bundle format
+	APIVersion string `json:"apiVersion" yaml:"apiVersion"`
+	// Kind is always ProfileBundle
+	Kind string `json:"kind" yaml:"kind"`
+	// Name is the name of the exported profile
+	Name string `json:"name" yaml:"name"`
+	// Server is the server of the profile, with any certificate authority inlined
+	Server *Server `json:"server" yaml:"server"`
+	// Workspace is the default workspace of the profile
+	Workspace string `json:"workspace,omitempty" yaml:"workspace,omitempty"`
+	// Credentials are the encrypted credentials of the profile, only present when requested
+	Credentials *EncryptedCredentials `json:"credentials,omitempty" yaml:"credentials,omitempty"`
+}
+
+// EncryptedCredentials holds the credentials of a profile encrypted with a passphrase
+type EncryptedCredentials struct {
+	// Cipher is the key derivation and encryption scheme used
+	Cipher string `json:"cipher" yaml:"cipher"`
+	// Iterations is the number of key derivation iterations
+	Iterations int `json:"iterations" yaml:"iterations"`
+	// Salt is the base64-encoded key derivation salt
+	Salt string `json:"salt" yaml:"salt"`
+	// Nonce is the base64-encoded cipher nonce
+	Nonce string `json:"nonce" yaml:"nonce"`
+	// Data is the base64-encoded encrypted credentials
+	Data string `json:"data" yaml:"data"`
+}
+
+// bundleCredentials are the secrets of a profile carried encrypted in a bundle
+type bundleCredentials struct {
+	AuthInfo          *AuthInfo `yaml:"user,omitempty"`
+	ClientCertificate string    `yaml:"clientCertificate,omitempty"`
+	ClientKey         string    `yaml:"clientKey,omitempty"`
+}
+
+// ExportOptions control what is included in an exported profile
+type ExportOptions struct {
+	// IncludeCredentials includes the user and client certificate of the profile, encrypted with
+	// the passphrase
+	IncludeCredentials bool
+	// Passphrase is used to encrypt the credentials, and is required when they are included
+	Passphrase string
+}
+
+// ImportOption is an option for importing a profile
+type ImportOption func(*importOptions)
+
+type importOptions struct {
+	passphrase    string
+	allowInsecure bool
+}
+
+// WithPassphrase provides the passphrase to decrypt the credentials of an imported profile. When
+// not provided, the credentials in a bundle are skipped.
+func WithPassphrase(passphrase string) ImportOption {
+	return func(o *importOptions) {
+		o.passphrase = passphrase
+	}
+}
+
+// WithInsecureSettings allows an imported profile to skip TLS verification or use a proxy. These
+// settings are rejected by default, as they allow whoever crafted the bundle to intercept requests.
+func WithInsecureSettings() ImportOption {
+	return func(o *importOptions) {
+		o.allowInsecure = true
+	}
+}
+
+// ExportProfile exports a profile as a portable YAML document holding the server endpoint,
+// certificate authority and default workspace. Settings specific to this machine, such as the
+// proxy, are not exported. Credentials are only included when requested.
+func (c *Config) ExportProfile(name string, options ExportOptions) ([]byte, error) {
+	if !c.HasProfile(name) {
+		return nil, ErrNoProfile
+	}
+	profile := c.Profiles[name]
+	if !c.HasServer(profile.Server) {
+		return nil, ErrNoProfileEndpoint
+	}
+	server := c.Servers[profile.Server]
+
+	bundle := &ProfileBundle{
+		APIVersion: ProfileBundleAPIVersion,
+		Kind:       ProfileBundleKind,
+		Name:       name,
+		Server: &Server{
+			Endpoint:              server.Endpoint,
+			PinnedPublicKey:       server.PinnedPublicKey,
+			InsecureSkipTLSVerify: server.InsecureSkipTLSVerify,
+			TLSServerName:         server.TLSServerName,
+		},
+		Workspace: profile.Workspace,
+	}
+	// the certificate authority is only ever inline, so is never read from a file
+	if server.CACertificate != "" {
+		if err := validateCertificates([]byte(server.CACertificate)); err != nil {
+			return nil, fmt.Errorf("certificate authority is invalid: %w", err)
+		}
+		bundle.Server.CACertificate = server.CACertificate
+	}
+
+	if options.IncludeCredentials {
+		creds, err := c.exportCredentials(profile.AuthInfo, server)
+		if err != nil {
+			return nil, err
+		}
+		if bundle.Credentials, err = encryptCredentials(creds, options.Passphrase); err != nil {
+			return nil, err
+		}
+	}
+
+	return yaml.Marshal(bundle)
+}
+
+// exportCredentials collects the user and client certificate of a profile, inlining any files
+func (c *Config) exportCredentials(user string, server *Server) (*bundleCredentials, error) {
+	creds := &bundleCredentials{}
+	if c.HasAuthInfo(user) {
+		creds.AuthInfo = c.AuthInfos[user]
+	}
+
+	if server.HasClientCertificate() {
+		cert, err := LoadPEM(server.ClientCertificate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		key, err := LoadPEM(server.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client key: %w", err)
+		}
+		creds.ClientCertificate, creds.ClientKey = string(cert), string(key)
+	}
+
+	return creds, nil
+}
+
+// ImportProfile validates and imports a profile exported by ExportProfile, returning the name of
+// the new profile. The profile is named rename if provided, which must not be in use, otherwise
+// the exported name is used with a numeric suffix added if it collides with an existing profile,
+// server or user. The current profile is not changed.
+func (c *Config) ImportProfile(doc []byte, rename string, options ...ImportOption) (string, error) {
+	opts := &importOptions{}
+	for _, o := range options {
+		o(opts)
+	}
+
+	bundle := &ProfileBundle{}
+	if err := yaml.UnmarshalStrict(doc, bundle); err != nil {
+		return "", fmt.Errorf("invalid profile bundle: %w", err)
+	}
+	if err := bundle.validate(opts); err != nil {
+		return "", err
+	}
+
+	var creds *bundleCredentials
+	if bundle.Credentials != nil && opts.passphrase != "" {
+		var err error
+		if creds, err = decryptCredentials(bundle.Credentials, opts.passphrase); err != nil {
+			return "", err
+		}
+		if err := creds.validate(); err != nil {
+			return "", err
+		}
+	}
+
+	name := rename
+	switch {
+	case name != "" && !c.isNameFree(name):
+		return "", fmt.Errorf("profile name %q already in use", name)
+	case name == "":
+		name = c.freeName(bundle.Name)
+	}
+
+	// @step: only the settings a bundle is expected to carry are imported
+	server := &Server{
+		Endpoint:        bundle.Server.Endpoint,
+		CACertificate:   bundle.Server.CACertificate,
+		TLSServerName:   bundle.Server.TLSServerName,
+		PinnedPublicKey: bundle.Server.PinnedPublicKey,
+	}
+	if opts.allowInsecure {
+		server.InsecureSkipTLSVerify = bundle.Server.InsecureSkipTLSVerify
+		server.ProxyURL, server.NoProxy = bundle.Server.ProxyURL, bundle.Server.NoProxy
+	}
+	if creds != nil && creds.ClientCertificate != "" {
+		server.ClientCertificate, server.ClientKey = creds.ClientCertificate, creds.ClientKey
+	}
+	c.AddServer(name, server)
+	c.AddProfile(name, &Profile{Server: name, AuthInfo: name, Workspace: bundle.Workspace})
+	if creds != nil && creds.AuthInfo != nil {
+		c.AddAuthInfo(name, creds.AuthInfo)
+	}
+
+	return name, nil
+}
+
+// validate checks the bundle is a profile bundle with a usable server, carrying nothing which
+// refers to files on this machine or weakens TLS without the user opting in
+func (b *ProfileBundle) validate(opts *importOptions) error {
+	verr := validation.NewError("profile bundle is invalid")
+
+	if b.APIVersion != ProfileBundleAPIVersion {
+		verr.AddFieldErrorf("apiVersion", validation.NotAllowed, "apiVersion %q is not supported, expected %q", b.APIVersion, ProfileBundleAPIVersion)
+	}
+	if b.Kind != ProfileBundleKind {
+		verr.AddFieldErrorf("kind", validation.NotAllowed, "kind %q is not supported, expected %q", b.Kind, ProfileBundleKind)
+	}
+	if b.Name == "" {
+		verr.AddFieldError("name", validation.Required, "profile name is not set")
+	}
+	if b.Server == nil {
+		verr.AddFieldError("server", validation.Required, "server is not set")
+	} else {
+		validateServer(verr, "server", b.Server)
+		if b.Server.CACertificate != "" && !isInlinePEM(b.Server.CACertificate) {
+			verr.AddFieldError("server.caCertificate", validation.NotAllowed, "certificate authority must be inline PEM")
+		}
+		if b.Server.ClientCertificate != "" || b.Server.ClientKey != "" {
+			verr.AddFieldError("server.clientCertificate", validation.NotAllowed, "client certificates can only be imported with the encrypted credentials")
+		}
+		if b.Server.APIInfo != nil {
+			verr.AddFieldError("server.apiInfo", validation.NotAllowed, "api paths cannot be imported")
+		}
+		if !opts.allowInsecure {
+			if b.Server.InsecureSkipTLSVerify {
+				verr.AddFieldError("server.insecureSkipTLSVerify", validation.NotAllowed, "skipping TLS verification must be explicitly allowed")
+			}
+			if b.Server.ProxyURL != "" || b.Server.NoProxy != "" {
+				verr.AddFieldError("server.proxyURL", validation.NotAllowed, "proxy settings must be explicitly allowed")
+			}
+		}
+	}
+	if b.Credentials != nil && b.Credentials.Cipher != bundleCipher {
+		verr.AddFieldErrorf("credentials.cipher", validation.NotAllowed, "cipher %q is not supported", b.Credentials.Cipher)
+	}
+
+	if errs := verr.GetNonWarnings(); len(errs) > 0 {
+		return &validation.Error{Code: verr.Code, Message: verr.Message, FieldErrors: errs}
+	}
+
+	return nil
+}
+
+// validate checks the credentials are a token or identity, and any client certificate is inline.
+// Credentials which read files or run commands on this machine are never imported.
+func (b *bundleCredentials) validate() error {
+	verr := validation.NewError("profile bundle is invalid")
+
+	if user := b.AuthInfo; user != nil {
+		switch {
+		case user.Exec != nil:
+			verr.AddFieldError("credentials.user.exec", validation.NotAllowed, "exec credentials cannot be imported")
+		case user.ServiceAccount != nil:
+			verr.AddFieldError("credentials.user.serviceAccount", validation.NotAllowed, "service account credentials cannot be imported")
+		}
+		validateAuthInfo(verr, "credentials.user", user)
+	}
+	if b.ClientCertificate != "" && !isInlinePEM(b.ClientCertificate) {
+		verr.AddFieldError("credentials.clientCertificate", validation.NotAllowed, "client certificate must be inline PEM")
+	}
+	if b.ClientKey != "" && !isInlinePEM(b.ClientKey) {
+		verr.AddFieldError("credentials.clientKey", validation.NotAllowed, "client key must be inline PEM")
+	}
+
+	if errs := verr.GetNonWarnings(); len(errs) > 0 {
+		return &validation.Error{Code: verr.Code, Message: verr.Message, FieldErrors: errs}
+	}
+
+	return nil
+}
+
+// isNameFree checks no profile, server or user is using the name
+func (c *Config) isNameFree(name string) bool {
+	return !c.HasProfile(name) && !c.HasServer(name) && !c.HasAuthInfo(name)
+}
+
+// freeName returns the name, or the name with the lowest numeric suffix which is not in use
+func (c *Config) freeName(name string) string {
+	candidate := name
+	for i := 2; !c.isNameFree(candidate); i++ {
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+
+	return candidate
+}
+
+// encryptCredentials encrypts the credentials with a key derived from the passphrase
+func encryptCredentials(creds *bundleCredentials, passphrase string) (*EncryptedCredentials, error) {
+	if passphrase == "" {
+		return nil, ErrPassphraseRequired
+	}
+	if len(passphrase) < bundleMinPassphrase {
+		return nil, fmt.Errorf("passphrase must be at least %d characters", bundleMinPassphrase)
+	}
+
+	plain, err := yaml.Marshal(creds)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := newBundleCipher(passphrase, salt, bundleIterations)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return &EncryptedCredentials{
+		Cipher:     bundleCipher,
+		Iterations: bundleIterations,
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Data:       base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, plain, []byte(bundleCipher))),
+	}, nil
+}
+
+// decryptCredentials decrypts the credentials with a key derived from the passphrase
+func decryptCredentials(enc *EncryptedCredentials, passphrase string) (*bundleCredentials, error) {
+	salt, err := base64.StdEncoding.DecodeString(enc.Salt)
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+	nonce, err := base64.StdEncoding.DecodeString(enc.Nonce)
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+	data, err := base64.StdEncoding.DecodeString(enc.Data)
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+	// the iterations come from the bundle, so only accept what we export rather than let a crafted
+	// bundle stall the import
+	if enc.Iterations != bundleIterations {
+		return nil, fmt.Errorf("unsupported key derivation iterations %d", enc.Iterations)
+	}
+
+	gcm, err := newBundleCipher(passphrase, salt, enc.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, ErrInvalidPassphrase
+	}
+	plain, err := gcm.Open(nil, nonce, data, []byte(enc.Cipher))
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+
+	creds := &bundleCredentials{}
+	if err := yaml.UnmarshalStrict(plain, creds); err != nil {
+		return nil, err
+	}
+
+	return creds, nil
+}
+
+// newBundleCipher returns an AES-256-GCM cipher keyed from the passphrase
+func newBundleCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2.Key([]byte(passphrase), salt, iterations, 32, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
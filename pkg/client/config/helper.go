@@ -20,10 +20,9 @@ import (
 	"os"
 	"path/filepath"
 
-	"gopkg.in/yaml.v2"
-
 	"github.com/appvia/wfclient/pkg/authtypes"
 	"github.com/appvia/wfclient/pkg/common"
+	"github.com/appvia/wfclient/pkg/version"
 )
 
 const (
@@ -32,18 +31,61 @@ const (
 	EnvWayfinderWorkspace = "WAYFINDER_WORKSPACE"
 )
 
+// IsEphemeralConfig checks if the configuration should be created from the environment rather
+// than read from the configuration files
 func IsEphemeralConfig() bool {
-	return os.Getenv(EnvWayfinderServer) != "" && os.Getenv(EnvWayfinderToken) != ""
+	return os.Getenv(EnvWayfinderServer) != "" &&
+		(os.Getenv(EnvWayfinderToken) != "" || os.Getenv(EnvWayfinderTokenFile) != "")
+}
+
+// GetConfigOption configures how GetConfig loads the configuration
+type GetConfigOption func(*getConfigOptions)
+
+type getConfigOptions struct {
+	strict bool
+}
+
+// UseStrictValidation makes GetConfig fail if the configuration is invalid, rather than failing
+// later when the invalid part of the configuration is used
+func UseStrictValidation() GetConfigOption {
+	return func(o *getConfigOptions) {
+		o.strict = true
+	}
 }
 
 // GetConfig returns either the ephemeral configuration from environment variables if provided, or
-// the current configured file - creating it if it does not exist.
-func GetConfig() (*Config, error) {
+// the current configured file - creating it if it does not exist. Any overrides set in the
+// environment are applied to the configuration, see OverridesFromEnv.
+func GetConfig(options ...GetConfigOption) (*Config, error) {
+	o := &getConfigOptions{}
+	for _, fn := range options {
+		fn(o)
+	}
+
+	overrides, err := OverridesFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *Config
 	if IsEphemeralConfig() {
-		return CreateEphemeralConfiguration(), nil
+		cfg = CreateEphemeralConfiguration()
+		// the ephemeral configuration only has the one profile
+		overrides.Profile = ""
+	} else {
+		if cfg, err = GetOrCreateClientConfiguration(); err != nil {
+			return nil, err
+		}
 	}
+	cfg.SetOverrides(overrides)
 
-	return GetOrCreateClientConfiguration()
+	if o.strict {
+		if err := cfg.IsValid(); err != nil {
+			return nil, err
+		}
+	}
+
+	return cfg, nil
 }
 
 // CreateEphemeralConfiguration creates a fake configuration from the environments
@@ -69,6 +111,7 @@ func CreateEphemeralConfiguration() *Config {
 	}
 
 	cfg.CreateProfile(name, server)
+	// a token file is applied from the overrides, see OverridesFromEnv
 	cfg.AddAuthInfo(name, &AuthInfo{Identity: identity})
 	cfg.AddServer(name, &Server{Endpoint: server})
 
@@ -93,14 +136,31 @@ func IsAccessToken(token []byte) bool {
 	return access && err == nil
 }
 
-// GetClientConfigurationPath returns the path to the client config
-func GetClientConfigurationPath() string {
-	// @step: retrieve the configuration path from env of default path
-	path := os.ExpandEnv(os.Getenv(DefaultWayfinderConfigPathEnv))
-	if path == "" {
-		path = os.ExpandEnv(DefaultWayfinderConfigPath)
+// GetClientConfigurationPaths returns the paths to the client configuration files. The
+// WAYFINDER_CONFIG environment variable may hold a list of files, separated as per PATH, in order
+// of precedence.
+func GetClientConfigurationPaths() []string {
+	// @step: retrieve the configuration paths from env of default path
+	var paths []string
+	for _, path := range filepath.SplitList(os.Getenv(DefaultWayfinderConfigPathEnv)) {
+		if path = os.ExpandEnv(path); path != "" {
+			paths = append(paths, absPath(path))
+		}
+	}
+	if len(paths) == 0 {
+		paths = append(paths, absPath(os.ExpandEnv(DefaultWayfinderConfigPath)))
 	}
 
+	return paths
+}
+
+// GetClientConfigurationPath returns the path to the primary client config, where new entries are
+// written when multiple files are in use
+func GetClientConfigurationPath() string {
+	return GetClientConfigurationPaths()[0]
+}
+
+func absPath(path string) string {
 	abs, err := filepath.Abs(path)
 	if err != nil {
 		return path
@@ -114,56 +174,82 @@ func GetClientPath() string {
 	return filepath.Dir(GetClientConfigurationPath())
 }
 
-// GetOrCreateClientConfiguration is responsible for retrieving the client configuration
+// GetOrCreateClientConfiguration is responsible for retrieving the client configuration, merging
+// all of the configured files
 var GetOrCreateClientConfiguration = func() (*Config, error) {
-	path := GetClientConfigurationPath()
-	common.LogWithoutContext().WithField("path", path).Debug("using wayfinder configration file")
+	paths := GetClientConfigurationPaths()
+	common.LogWithoutContext().WithField("paths", paths).Debug("using wayfinder configration files")
 
-	// @step: check the file exists else create it
-	if found, err := fileExists(path); err != nil {
+	// @step: read the configuration, treating missing or empty files as empty configuration
+	config, err := Load(paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	// @step: we need to write an empty file for now if we have nothing at all
+	if found, err := anyFileExists(paths); err != nil {
 		return nil, err
 	} else if !found {
-		// @step: we need to write an empty file for now
-		if err := UpdateConfig(NewEmpty(), path); err != nil {
+		config.Version = version.Release
+		config.SchemaVersion = CurrentSchemaVersion()
+		if err := config.Save(); err != nil {
 			return nil, err
 		}
+	}
+
+	return config, nil
+}
 
-		return NewEmpty(), nil
+// UpdateConfig is responsible for writing the configuration to disk. The file is locked for the
+// duration of the update and replaced atomically. Where the configuration was read from disk, only
+// the entries changed since then are written, preserving any changes made by other processes in the
+// meantime (such as another process refreshing the token of a different profile). Nothing is
+// written if WAYFINDER_CONFIG_READONLY is set.
+var UpdateConfig = func(config *Config, path string) error {
+	if IsConfigReadOnly() {
+		common.LogWithoutContext().WithField("path", path).Debug("configuration is read-only, not writing")
+		return nil
 	}
 
-	// @step: open the configuration file for reading
-	file, err := os.Open(path)
+	unlock, err := LockConfig(path)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer unlock()
 
-	return New(file)
-}
-
-// UpdateConfig is responsible for writing the configuration to disk
-var UpdateConfig = func(config *Config, path string) error {
-	data, err := yaml.Marshal(config)
+	// @step: re-read the current state so we do not clobber changes from other processes
+	current, err := readConfigFile(path)
 	if err != nil {
 		return err
 	}
 
-	if err := os.MkdirAll(filepath.Dir(path), os.FileMode(0750)); err != nil {
+	merged := config
+	if current != nil && config.persisted != nil {
+		merged = mergeConfig(config.persisted, config, current)
+	}
+
+	if err := writeConfigFile(merged, path); err != nil {
 		return err
 	}
+	config.markPersisted()
 
-	return os.WriteFile(path, data, os.FileMode(0640))
+	return nil
 }
 
-// fileExists checks if a file exists
-func fileExists(filename string) (bool, error) {
-	info, err := os.Stat(filename)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
+// anyFileExists checks if any of the files exist
+func anyFileExists(paths []string) (bool, error) {
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return false, err
+		}
+		if !info.IsDir() {
+			return true, nil
 		}
-
-		return false, err
 	}
 
-	return !info.IsDir(), nil
+	return false, nil
 }
@@ -0,0 +1,237 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+)
+
+const (
+	originProfile  = "profiles"
+	originServer   = "servers"
+	originAuthInfo = "users"
+)
+
+// Origin describes the files in which the parts of a profile were defined
+type Origin struct {
+	// Profile is the file defining the profile
+	Profile string `json:"profile,omitempty" yaml:"profile,omitempty"`
+	// Server is the file defining the server used by the profile
+	Server string `json:"server,omitempty" yaml:"server,omitempty"`
+	// AuthInfo is the file defining the credentials used by the profile
+	AuthInfo string `json:"user,omitempty" yaml:"user,omitempty"`
+}
+
+// Load reads and merges the configuration files at the provided paths, any of which may not
+// exist. Files written with an older schema are migrated in memory, and the migration is only
+// written back when Save next writes the file. Where a profile, server or user is defined in
+// more than one file, the definition in the earliest file wins, as does the first current
+// profile set. Updates made with Save are written back to the file each entry came from, with
+// new entries written to the first file.
+func Load(paths ...string) (*Config, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no configuration files provided")
+	}
+
+	merged := NewEmpty()
+	merged.Version = ""
+	merged.sources = paths
+	merged.origins = make(map[string]string)
+	merged.files = make(map[string]*Config)
+	merged.migratedFiles = make(map[string]bool)
+
+	for _, path := range paths {
+		file, err := readConfigFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read configuration file %s: %w", path, err)
+		}
+		if file == nil {
+			file = NewEmpty()
+			file.Version = ""
+			file.markPersisted()
+		}
+		merged.files[path] = file.persisted
+		if file.migrated {
+			merged.migratedFiles[path] = true
+		}
+
+		loadEntries(merged.origins, originProfile, path, file.Profiles, merged.Profiles)
+		loadEntries(merged.origins, originServer, path, file.Servers, merged.Servers)
+		loadEntries(merged.origins, originAuthInfo, path, file.AuthInfos, merged.AuthInfos)
+
+		if merged.CurrentProfile == "" && file.CurrentProfile != "" {
+			merged.CurrentProfile = file.CurrentProfile
+			merged.origins[originKey("current-profile", "")] = path
+		}
+		if merged.Version == "" {
+			merged.Version = file.Version
+		}
+		if file.SchemaVersion > merged.SchemaVersion {
+			merged.SchemaVersion = file.SchemaVersion
+		}
+	}
+	merged.markPersisted()
+
+	return merged, nil
+}
+
+func loadEntries[T any](origins map[string]string, kind, path string, from, into map[string]*T) {
+	for name, entry := range from {
+		if _, found := into[name]; found {
+			continue
+		}
+		into[name] = entry
+		origins[originKey(kind, name)] = path
+	}
+}
+
+func originKey(kind, name string) string {
+	return kind + "/" + name
+}
+
+// Sources returns the files the configuration was loaded from, if any
+func (c *Config) Sources() []string {
+	return c.sources
+}
+
+// Origin returns the files defining the named profile and the server and user it refers to. Entries
+// which have not yet been saved, or configurations not loaded from files, have no origin.
+func (c *Config) Origin(name string) Origin {
+	origin := Origin{Profile: c.origins[originKey(originProfile, name)]}
+	if p, found := c.Profiles[name]; found && p != nil {
+		origin.Server = c.origins[originKey(originServer, p.Server)]
+		origin.AuthInfo = c.origins[originKey(originAuthInfo, p.AuthInfo)]
+	}
+
+	return origin
+}
+
+// Save writes the configuration back to the files it was loaded from, with each entry written to
+// the file it came from and new entries written to the first file.
+func (c *Config) Save() error {
+	if len(c.sources) == 0 {
+		return fmt.Errorf("configuration was not loaded from a file")
+	}
+	if len(c.sources) == 1 {
+		return c.saveFile(c.sources[0], c)
+	}
+
+	primary := c.sources[0]
+	owner := func(kind, name string) string {
+		if path, found := c.origins[originKey(kind, name)]; found {
+			return path
+		}
+		return primary
+	}
+
+	for _, path := range c.sources {
+		base := c.files[path]
+		if base == nil {
+			base = &Config{}
+		}
+		file := base.clone()
+		file.AuthInfos = saveEntries(c.persisted.AuthInfos, c.AuthInfos, file.AuthInfos, func(name string) bool {
+			return owner(originAuthInfo, name) == path
+		})
+		file.Profiles = saveEntries(c.persisted.Profiles, c.Profiles, file.Profiles, func(name string) bool {
+			return owner(originProfile, name) == path
+		})
+		file.Servers = saveEntries(c.persisted.Servers, c.Servers, file.Servers, func(name string) bool {
+			return owner(originServer, name) == path
+		})
+		if owner("current-profile", "") == path {
+			file.CurrentProfile = c.CurrentProfile
+		}
+		if path == primary && file.Version == "" {
+			file.Version = c.Version
+			file.SchemaVersion = c.SchemaVersion
+		}
+		file.persisted = base
+
+		// skip secondary files which have not changed, so we only write the files we need to
+		if path != primary && equalConfig(file, base) {
+			continue
+		}
+		if err := c.saveFile(path, file); err != nil {
+			return err
+		}
+		c.files[path] = file.persisted
+	}
+
+	// anything new now lives in the primary file
+	for name := range c.Profiles {
+		c.setOrigin(originProfile, name, primary)
+	}
+	for name := range c.Servers {
+		c.setOrigin(originServer, name, primary)
+	}
+	for name := range c.AuthInfos {
+		c.setOrigin(originAuthInfo, name, primary)
+	}
+	if c.CurrentProfile != "" {
+		c.setOrigin("current-profile", "", primary)
+	}
+	c.markPersisted()
+
+	return nil
+}
+
+func (c *Config) saveFile(path string, file *Config) error {
+	if c.migratedFiles[path] && !IsConfigReadOnly() {
+		if err := backupConfigFile(path); err != nil {
+			return err
+		}
+	}
+	if err := UpdateConfig(file, path); err != nil {
+		return fmt.Errorf("failed to write configuration file %s: %w", path, err)
+	}
+	if c.files != nil {
+		c.files[path] = file.persisted
+	}
+	delete(c.migratedFiles, path)
+
+	return nil
+}
+
+func (c *Config) setOrigin(kind, name, path string) {
+	if c.origins == nil {
+		c.origins = make(map[string]string)
+	}
+	if _, found := c.origins[originKey(kind, name)]; !found {
+		c.origins[originKey(kind, name)] = path
+	}
+}
+
+// saveEntries writes every entry the file owns into the file, and deletes the entries it owns
+// which have been removed since the configuration was loaded
+func saveEntries[T any](base, ours, file map[string]*T, owns func(string) bool) map[string]*T {
+	if file == nil {
+		file = make(map[string]*T)
+	}
+	for name, entry := range ours {
+		if owns(name) {
+			file[name] = entry
+		}
+	}
+	for name := range base {
+		if _, found := ours[name]; !found && owns(name) {
+			delete(file, name)
+		}
+	}
+
+	return file
+}
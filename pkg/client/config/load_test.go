@@ -0,0 +1,120 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTeamConfig = `
+current-profile: team
+profiles:
+  prod:
+    server: prod
+    user: prod
+  team:
+    server: team
+    user: team
+servers:
+  prod:
+    server: https://prod.example.com
+    caCertificate: team-ca
+  team:
+    server: https://team.example.com
+`
+	testPersonalConfig = `
+current-profile: prod
+profiles:
+  prod:
+    server: prod
+    user: prod
+users:
+  prod:
+    identity:
+      token: personal
+`
+)
+
+func writeTestConfigFiles(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	personal := filepath.Join(dir, "personal")
+	team := filepath.Join(dir, "team")
+	require.NoError(t, os.WriteFile(personal, []byte(testPersonalConfig), 0600))
+	require.NoError(t, os.WriteFile(team, []byte(testTeamConfig), 0600))
+
+	return personal, team
+}
+
+func TestLoadMergesFiles(t *testing.T) {
+	personal, team := writeTestConfigFiles(t)
+
+	c, err := Load(personal, team, filepath.Join(filepath.Dir(personal), "missing"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "prod", c.CurrentProfile)
+	assert.ElementsMatch(t, []string{"prod", "team"}, c.ListProfiles())
+	assert.Equal(t, "team-ca", c.GetServer("prod").CACertificate)
+	assert.Equal(t, "personal", c.GetAuthInfo("prod").Identity.Token)
+
+	assert.Equal(t, Origin{Profile: personal, Server: team, AuthInfo: personal}, c.Origin("prod"))
+	assert.Equal(t, Origin{Profile: team, Server: team}, c.Origin("team"))
+	assert.Equal(t, Origin{}, c.Origin("missing"))
+}
+
+func TestLoadSaveWritesToOrigin(t *testing.T) {
+	personal, team := writeTestConfigFiles(t)
+
+	c, err := Load(personal, team)
+	require.NoError(t, err)
+	teamBefore, err := os.ReadFile(team)
+	require.NoError(t, err)
+
+	// a token refresh and a new profile only touch the personal file
+	c.GetAuthInfo("prod").Identity.Token = "refreshed"
+	c.CreateProfile("dev", "https://dev.example.com")
+	require.NoError(t, c.Save())
+
+	teamAfter, err := os.ReadFile(team)
+	require.NoError(t, err)
+	assert.Equal(t, string(teamBefore), string(teamAfter))
+
+	p := readTestConfigFile(t, personal)
+	assert.Equal(t, "refreshed", p.AuthInfos["prod"].Identity.Token)
+	assert.True(t, p.HasProfile("dev"))
+	assert.Equal(t, personal, c.Origin("dev").Profile)
+
+	// changes to the shared server go back to the team file
+	c.GetServer("prod").CACertificate = "rotated-ca"
+	require.NoError(t, c.Save())
+
+	tc := readTestConfigFile(t, team)
+	assert.Equal(t, "rotated-ca", tc.Servers["prod"].CACertificate)
+	assert.False(t, readTestConfigFile(t, personal).HasServer("prod"))
+}
+
+func TestGetClientConfigurationPaths(t *testing.T) {
+	t.Setenv(DefaultWayfinderConfigPathEnv, strings.Join([]string{"/a/config", "", "/b/config"}, string(os.PathListSeparator)))
+	assert.Equal(t, []string{"/a/config", "/b/config"}, GetClientConfigurationPaths())
+	assert.Equal(t, "/a/config", GetClientConfigurationPath())
+}
@@ -0,0 +1,194 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/appvia/wfclient/pkg/common"
+	"github.com/appvia/wfclient/pkg/version"
+)
+
+// Migration upgrades a configuration document to a new schema version. Migrations operate on the
+// raw YAML document, so they can rename and move fields which no longer exist on Config.
+type Migration struct {
+	// Version is the schema version the migration upgrades the document to
+	Version int
+	// Description describes the change made by the migration
+	Description string
+	// Migrate applies the change to the document
+	Migrate func(doc map[interface{}]interface{}) error
+}
+
+// migrations are the registered migrations, in version order
+var migrations []Migration
+
+func init() {
+	RegisterMigration(Migration{
+		Version:     1,
+		Description: "normalise the keys of cached API information on servers",
+		Migrate:     migrateAPIInfoKeys,
+	})
+}
+
+// RegisterMigration registers a migration to run when a configuration with an older schema version
+// is loaded
+func RegisterMigration(m Migration) {
+	for _, existing := range migrations {
+		if existing.Version == m.Version {
+			panic(fmt.Sprintf("configuration migration %d is already registered", m.Version))
+		}
+	}
+	migrations = append(migrations, m)
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+}
+
+// CurrentSchemaVersion returns the schema version written by this client
+func CurrentSchemaVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+
+	return migrations[len(migrations)-1].Version
+}
+
+// migrateConfigData applies any outstanding migrations to the encoded configuration, returning the
+// migrated document and whether the schema was migrated
+func migrateConfigData(data []byte) ([]byte, bool, error) {
+	doc := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, false, err
+	}
+
+	migrated, err := migrateDocument(doc)
+	if err != nil {
+		return nil, false, err
+	}
+	refreshed := refreshDocument(doc)
+	if !migrated && !refreshed {
+		return data, false, nil
+	}
+
+	encoded, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return encoded, migrated, nil
+}
+
+// migrateDocument runs the migrations newer than the document's schema version, returning whether
+// any were run
+func migrateDocument(doc map[interface{}]interface{}) (bool, error) {
+	changed := false
+
+	from, _ := doc["schemaVersion"].(int)
+	if from > CurrentSchemaVersion() {
+		return false, fmt.Errorf("configuration schema version %d is newer than this client supports (%d), please upgrade", from, CurrentSchemaVersion())
+	}
+	for _, m := range migrations {
+		if m.Version <= from {
+			continue
+		}
+		common.LogWithoutContext().WithField("version", m.Version).Debugf("migrating configuration: %s", m.Description)
+
+		if err := m.Migrate(doc); err != nil {
+			return false, fmt.Errorf("failed to migrate configuration to schema version %d: %w", m.Version, err)
+		}
+		doc["schemaVersion"] = m.Version
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// refreshDocument drops any cached data which may be stale where the document was written by a
+// different client version. This is not a schema change, so is not a reason to rewrite the file.
+func refreshDocument(doc map[interface{}]interface{}) bool {
+	if written, _ := doc["version"].(string); written == version.Release {
+		return false
+	}
+	forEachServer(doc, func(server map[interface{}]interface{}) {
+		delete(server, "apiInfo")
+	})
+	doc["version"] = version.Release
+
+	return true
+}
+
+// backupConfigFile copies the configuration file at path before a migration is first written to
+// it, so the original can be restored
+func backupConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	backup := fmt.Sprintf("%s.%s.bak", path, time.Now().UTC().Format("20060102T150405"))
+	if err := os.WriteFile(backup, data, os.FileMode(0600)); err != nil {
+		return fmt.Errorf("failed to back up configuration before migration: %w", err)
+	}
+	common.LogWithoutContext().WithField("backup", backup).Debug("backed up configuration before writing migration")
+
+	return nil
+}
+
+// migrateAPIInfoKeys moves cached API information written without explicit YAML keys to the
+// camel-cased keys used by the JSON representation
+func migrateAPIInfoKeys(doc map[interface{}]interface{}) error {
+	renames := map[string]string{
+		"nonresourceapi": "nonResourceAPI",
+		"resourceapi":    "resourceAPI",
+		"kubeproxyapi":   "kubeProxyAPI",
+	}
+
+	forEachServer(doc, func(server map[interface{}]interface{}) {
+		info, found := server["apiinfo"].(map[interface{}]interface{})
+		delete(server, "apiinfo")
+		if !found {
+			return
+		}
+		for from, to := range renames {
+			if v, found := info[from]; found {
+				info[to] = v
+				delete(info, from)
+			}
+		}
+		server["apiInfo"] = info
+	})
+
+	return nil
+}
+
+func forEachServer(doc map[interface{}]interface{}, fn func(map[interface{}]interface{})) {
+	servers, _ := doc["servers"].(map[interface{}]interface{})
+	for _, s := range servers {
+		if server, ok := s.(map[interface{}]interface{}); ok {
+			fn(server)
+		}
+	}
+}
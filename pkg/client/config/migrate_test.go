@@ -0,0 +1,153 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/appvia/wfclient/pkg/version"
+)
+
+const testLegacyConfig = `
+current-profile: local
+profiles:
+  local:
+    server: local
+    user: local
+servers:
+  local:
+    server: https://wayfinder.example.com
+    apiinfo:
+      nonresourceapi: /api/v2
+      resourceapi: /resources
+      kubeproxyapi: /kubeproxy
+users:
+  local:
+    token: static
+version: %s
+`
+
+func TestMigrateAPIInfoKeys(t *testing.T) {
+	c, err := New(strings.NewReader(strings.Replace(testLegacyConfig, "%s", version.Release, 1)))
+	require.NoError(t, err)
+
+	assert.Equal(t, CurrentSchemaVersion(), c.SchemaVersion)
+	require.NotNil(t, c.GetServer("local").APIInfo)
+	assert.Equal(t, APIInfo{NonResourceAPI: "/api/v2", ResourceAPI: "/resources", KubeProxyAPI: "/kubeproxy"}, *c.GetServer("local").APIInfo)
+}
+
+func TestMigrateDropsStaleAPIInfo(t *testing.T) {
+	c, err := New(strings.NewReader(strings.Replace(testLegacyConfig, "%s", "2.9.0", 1)))
+	require.NoError(t, err)
+
+	assert.Nil(t, c.GetServer("local").APIInfo)
+	assert.Equal(t, version.Release, c.Version)
+}
+
+func TestMigrateNewerSchema(t *testing.T) {
+	_, err := New(strings.NewReader("schemaVersion: 9999\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "please upgrade")
+}
+
+func TestMigrateConfigFileOnSave(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+	original := strings.Replace(testLegacyConfig, "%s", version.Release, 1)
+	require.NoError(t, os.WriteFile(path, []byte(original), 0600))
+
+	// loading migrates in memory, leaving the file untouched
+	c, err := Load(path)
+	require.NoError(t, err)
+	assert.NotNil(t, c.GetServer("local").APIInfo)
+	unchanged, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(unchanged))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// the migration is written, with a backup, when the file is saved
+	require.NoError(t, c.Save())
+	backups, err := filepath.Glob(path + ".*.bak")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	backup, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, original, string(backup))
+
+	migrated, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(migrated), "apiInfo:")
+	assert.Contains(t, string(migrated), "nonResourceAPI: /api/v2")
+
+	// there is nothing left to migrate
+	require.NoError(t, c.Save())
+	c, err = Load(path)
+	require.NoError(t, err)
+	require.NoError(t, c.Save())
+	backups, err = filepath.Glob(path + ".*.bak")
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+}
+
+func TestLoadOlderClientVersionIsNotMigration(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(path, []byte("schemaVersion: 1\nversion: 2.9.0\ncurrent-profile: local\n"), 0600))
+
+	c, err := Load(path)
+	require.NoError(t, err)
+	require.NoError(t, c.Save())
+
+	backups, err := filepath.Glob(path + ".*.bak")
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+}
+
+func TestRegisterMigration(t *testing.T) {
+	saved := migrations
+	defer func() { migrations = saved }()
+	migrations = append([]Migration{}, saved...)
+
+	RegisterMigration(Migration{
+		Version:     CurrentSchemaVersion() + 1,
+		Description: "rename current-context",
+		Migrate: func(doc map[interface{}]interface{}) error {
+			if v, found := doc["current-context"]; found {
+				doc["current-profile"] = v
+				delete(doc, "current-context")
+			}
+			return nil
+		},
+	})
+
+	c, err := New(strings.NewReader("current-context: demo\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "demo", c.CurrentProfile)
+	assert.Equal(t, CurrentSchemaVersion(), c.SchemaVersion)
+
+	assert.Panics(t, func() {
+		RegisterMigration(Migration{Version: 1})
+	})
+}
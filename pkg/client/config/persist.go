@@ -0,0 +1,184 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// LockTimeout is how long we wait to acquire the lock on the configuration file
+	LockTimeout = 30 * time.Second
+	// lockPollInterval is how often we retry a lock held by another process
+	lockPollInterval = 50 * time.Millisecond
+)
+
+// LockConfig takes an advisory lock on the configuration file at path, returning a function to
+// release it. The lock is held on a separate lock file so that the configuration file itself can be
+// replaced atomically while the lock is held.
+func LockConfig(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.FileMode(0750)); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, os.FileMode(0640))
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(LockTimeout)
+	for {
+		locked, err := tryLockFile(file)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		if locked {
+			break
+		}
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("timed out waiting for lock on configuration file %s", path)
+		}
+		time.Sleep(lockPollInterval)
+	}
+
+	return func() {
+		_ = unlockFile(file)
+		file.Close()
+	}, nil
+}
+
+// readConfigFile reads the configuration at path, returning nil if it does not exist
+func readConfigFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// an empty file is treated as no configuration rather than an error
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	return New(bytes.NewReader(data))
+}
+
+// writeConfigFile writes the configuration to path atomically, by writing to a temporary file in
+// the same directory and renaming it over the original.
+func writeConfigFile(config *Config, path string) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), os.FileMode(0640)); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// clone returns a deep copy of the configuration
+func (c *Config) clone() *Config {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil
+	}
+	cp := &Config{}
+	if err := yaml.Unmarshal(data, cp); err != nil {
+		return nil
+	}
+
+	return cp
+}
+
+// equalConfig checks if two configurations would be written identically
+func equalConfig(a, b *Config) bool {
+	ad, aerr := yaml.Marshal(a)
+	bd, berr := yaml.Marshal(b)
+
+	return aerr == nil && berr == nil && bytes.Equal(ad, bd)
+}
+
+// markPersisted records the configuration as matching what is on disk, so later updates can
+// tell which entries have been changed in memory
+func (c *Config) markPersisted() {
+	c.persisted = c.clone()
+}
+
+// mergeConfig applies the changes made in ours since it was loaded as base on top of theirs, the
+// current configuration on disk, so that entries changed by other processes are preserved
+func mergeConfig(base, ours, theirs *Config) *Config {
+	merged := theirs.clone()
+
+	merged.AuthInfos = mergeEntries(base.AuthInfos, ours.AuthInfos, merged.AuthInfos)
+	merged.Profiles = mergeEntries(base.Profiles, ours.Profiles, merged.Profiles)
+	merged.Servers = mergeEntries(base.Servers, ours.Servers, merged.Servers)
+	if ours.CurrentProfile != base.CurrentProfile {
+		merged.CurrentProfile = ours.CurrentProfile
+	}
+	if ours.Version != base.Version {
+		merged.Version = ours.Version
+	}
+
+	return merged
+}
+
+func mergeEntries[T any](base, ours, theirs map[string]*T) map[string]*T {
+	if theirs == nil {
+		theirs = make(map[string]*T)
+	}
+	for name, entry := range ours {
+		if !reflect.DeepEqual(entry, base[name]) {
+			theirs[name] = entry
+		}
+	}
+	for name := range base {
+		if _, found := ours[name]; !found {
+			delete(theirs, name)
+		}
+	}
+
+	return theirs
+}
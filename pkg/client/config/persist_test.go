@@ -0,0 +1,123 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readTestConfigFile(t *testing.T, path string) *Config {
+	c, err := readConfigFile(path)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+
+	return c
+}
+
+func TestUpdateConfigPreservesOtherChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+
+	initial := NewEmpty()
+	initial.CreateProfile("a", "https://a.example.com")
+	initial.AddAuthInfo("a", &AuthInfo{Identity: &Identity{Token: "a1"}})
+	initial.CreateProfile("b", "https://b.example.com")
+	initial.AddAuthInfo("b", &AuthInfo{Identity: &Identity{Token: "b1"}})
+	require.NoError(t, UpdateConfig(initial, path))
+
+	// two processes load the same configuration and each refresh a different profile
+	first := readTestConfigFile(t, path)
+	second := readTestConfigFile(t, path)
+
+	first.AuthInfos["a"].Identity.Token = "a2"
+	require.NoError(t, UpdateConfig(first, path))
+
+	second.AuthInfos["b"].Identity.Token = "b2"
+	require.NoError(t, UpdateConfig(second, path))
+
+	result := readTestConfigFile(t, path)
+	assert.Equal(t, "a2", result.AuthInfos["a"].Identity.Token)
+	assert.Equal(t, "b2", result.AuthInfos["b"].Identity.Token)
+}
+
+func TestUpdateConfigRemovesProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+
+	initial := NewEmpty()
+	initial.CreateProfile("a", "https://a.example.com")
+	initial.CreateProfile("b", "https://b.example.com")
+	require.NoError(t, UpdateConfig(initial, path))
+
+	c := readTestConfigFile(t, path)
+	c.RemoveProfile("b")
+	require.NoError(t, UpdateConfig(c, path))
+
+	result := readTestConfigFile(t, path)
+	assert.True(t, result.HasProfile("a"))
+	assert.False(t, result.HasProfile("b"))
+	assert.False(t, result.HasServer("b"))
+}
+
+func TestUpdateConfigConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, UpdateConfig(NewEmpty(), path))
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c, err := readConfigFile(path)
+			if !assert.NoError(t, err) {
+				return
+			}
+			if c == nil {
+				c = NewEmpty()
+			}
+			name := fmt.Sprintf("profile-%d", i)
+			c.CreateProfile(name, "https://wayfinder.example.com")
+			assert.NoError(t, UpdateConfig(c, path))
+		}(i)
+	}
+	wg.Wait()
+
+	result := readTestConfigFile(t, path)
+	assert.Len(t, result.Profiles, 10)
+
+	// no temporary files should be left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	for _, e := range entries {
+		assert.Contains(t, []string{"config", "config.lock"}, e.Name())
+	}
+}
+
+func TestGetOrCreateClientConfigurationEmptyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(path, nil, 0600))
+	t.Setenv(DefaultWayfinderConfigPathEnv, path)
+
+	c, err := GetOrCreateClientConfiguration()
+	require.NoError(t, err)
+	assert.Empty(t, c.Profiles)
+}
@@ -0,0 +1,76 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadPEM returns the PEM data provided inline, or read from the file at the path provided
+func LoadPEM(value string) ([]byte, error) {
+	if isInlinePEM(value) {
+		return []byte(value), nil
+	}
+
+	data, err := os.ReadFile(os.ExpandEnv(value))
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// isInlinePEM checks the value is PEM data rather than a path to a file
+func isInlinePEM(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN")
+}
+
+// HasClientCertificate checks if the server is configured for mutual TLS
+func (s *Server) HasClientCertificate() bool {
+	return s.ClientCertificate != "" || s.ClientKey != ""
+}
+
+// LoadClientCertificate loads the client certificate and key, returning nil if there is no client
+// certificate configured
+func (s *Server) LoadClientCertificate() (*tls.Certificate, error) {
+	if !s.HasClientCertificate() {
+		return nil, nil
+	}
+	if s.ClientCertificate == "" || s.ClientKey == "" {
+		return nil, errors.New("both a client certificate and key must be provided")
+	}
+
+	cert, err := LoadPEM(s.ClientCertificate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+	key, err := LoadPEM(s.ClientKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client key: %w", err)
+	}
+
+	pair, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client certificate: %w", err)
+	}
+
+	return &pair, nil
+}
@@ -0,0 +1,215 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/appvia/wfclient/pkg/common"
+)
+
+var (
+	// ErrServerNotTrusted indicates the user declined to trust the certificate of a server
+	ErrServerNotTrusted = errors.New("server certificate was not trusted")
+	// trustDialTimeout is how long we wait to connect to a server to retrieve its certificates
+	trustDialTimeout = 10 * time.Second
+	// systemRoots are the certificate authorities trusted by the system, nil for the system pool
+	systemRoots *x509.CertPool
+)
+
+// TrustInfo describes the certificates presented by a server which is not trusted by the system
+type TrustInfo struct {
+	// Endpoint is the endpoint of the server
+	Endpoint string
+	// Subject is the subject of the server certificate
+	Subject string
+	// DNSNames are the names the server certificate is valid for
+	DNSNames []string
+	// Issuer is the subject of the certificate authority which will be trusted
+	Issuer string
+	// Fingerprint is the SHA-256 fingerprint of the certificate authority which will be trusted
+	Fingerprint string
+	// PublicKeyPin is the pin of the server public key, which will be checked on later connections
+	PublicKeyPin string
+	// PreviousPublicKeyPin is the pin previously trusted for the server, if any
+	PreviousPublicKeyPin string
+	// NotAfter is when the server certificate expires
+	NotAfter time.Time
+}
+
+// TrustPrompt decides whether to trust the certificates presented by a server, typically by
+// showing the fingerprint to the user and asking for confirmation
+type TrustPrompt func(info TrustInfo) (bool, error)
+
+// CreateProfileWithTrust creates a profile for the endpoint. If the endpoint uses https and its
+// certificate is not trusted by the system, the prompt is used to decide whether to trust it on
+// first use, in which case the certificate authority is stored and the server public key pinned.
+func (c *Config) CreateProfileWithTrust(name, endpoint string, prompt TrustPrompt) error {
+	c.AddProfile(name, &Profile{
+		Server:   name,
+		AuthInfo: name,
+	})
+	c.AddServer(name, &Server{Endpoint: endpoint})
+
+	return c.TrustServer(name, prompt)
+}
+
+// TrustServer retrieves the certificates presented by the named server and, if they are not
+// trusted by the system, uses the prompt to decide whether to trust them. On confirmation the
+// certificate authority is stored and the server public key pinned. This is also used to trust a
+// server again after its key has changed.
+func (c *Config) TrustServer(name string, prompt TrustPrompt) error {
+	server := c.Servers[name]
+	if server == nil {
+		return fmt.Errorf("server %q does not exist", name)
+	}
+	u, err := url.Parse(server.Endpoint)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" {
+		return nil
+	}
+
+	chain, err := FetchServerCertificates(server.Endpoint, server.TLSServerName)
+	if err != nil {
+		return err
+	}
+	serverName := server.TLSServerName
+	if serverName == "" {
+		serverName = u.Hostname()
+	}
+	// a pin taken before the server moved to a trusted certificate would fail every connection
+	if IsSystemTrusted(chain, serverName) {
+		server.PinnedPublicKey = ""
+		return nil
+	}
+
+	leaf, ca := chain[0], chain[len(chain)-1]
+	info := TrustInfo{
+		Endpoint:             server.Endpoint,
+		Subject:              leaf.Subject.String(),
+		DNSNames:             leaf.DNSNames,
+		Issuer:               ca.Subject.String(),
+		Fingerprint:          CertificateFingerprint(ca),
+		PublicKeyPin:         PublicKeyPin(leaf),
+		PreviousPublicKeyPin: server.PinnedPublicKey,
+		NotAfter:             leaf.NotAfter,
+	}
+
+	trusted, err := prompt(info)
+	if err != nil {
+		return err
+	}
+	if !trusted {
+		return ErrServerNotTrusted
+	}
+
+	server.CACertificate = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}))
+	server.PinnedPublicKey = info.PublicKeyPin
+
+	return nil
+}
+
+// FetchServerCertificates connects to the endpoint and returns the certificate chain it presents,
+// without verifying it
+func FetchServerCertificates(endpoint, serverName string) ([]*x509.Certificate, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "443")
+	}
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: trustDialTimeout}, "tcp", host, &tls.Config{
+		// we are retrieving the certificates to verify them ourselves
+		InsecureSkipVerify: true, //nolint:gosec
+		ServerName:         serverName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", host, err)
+	}
+	defer conn.Close()
+
+	chain := conn.ConnectionState().PeerCertificates
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("%s did not present any certificates", host)
+	}
+
+	return chain, nil
+}
+
+// IsSystemTrusted checks if the chain is trusted by the system certificate authorities for the
+// server name
+func IsSystemTrusted(chain []*x509.Certificate, serverName string) bool {
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := chain[0].Verify(x509.VerifyOptions{Roots: systemRoots, DNSName: serverName, Intermediates: intermediates})
+
+	return err == nil
+}
+
+// CertificateFingerprint returns the SHA-256 fingerprint of the certificate, as colon-separated
+// hex in the form commonly shown by browsers and openssl
+func CertificateFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+
+	return strings.Join(parts, ":")
+}
+
+// PublicKeyPin returns the pin of the certificate's public key: the base64 SHA-256 hash of its
+// subject public key info, prefixed with the hash algorithm
+func PublicKeyPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// localhostCA returns the certificate of a local development server to trust, where the certificate
+// is valid for localhost. Only the certificate is trusted, the server public key is not pinned.
+func localhostCA(endpoint string) string {
+	chain, err := FetchServerCertificates(endpoint, "")
+	if err != nil {
+		common.LogWithoutContext().Debugf("failed to retrieve certificate of %s: %s", endpoint, err.Error())
+		return ""
+	}
+	for _, domain := range chain[len(chain)-1].DNSNames {
+		if domain == "localhost" {
+			return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: chain[0].Raw}))
+		}
+	}
+	common.LogWithoutContext().WithField("endpoint", endpoint).Debug("certificate is not valid for localhost, not trusting")
+
+	return ""
+}
@@ -0,0 +1,94 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateProfileWithTrust(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	var seen TrustInfo
+	c := NewEmpty()
+	require.NoError(t, c.CreateProfileWithTrust("internal", server.URL, func(info TrustInfo) (bool, error) {
+		seen = info
+		return true, nil
+	}))
+
+	assert.Equal(t, server.URL, seen.Endpoint)
+	assert.Equal(t, CertificateFingerprint(server.Certificate()), seen.Fingerprint)
+	assert.Len(t, strings.Split(seen.Fingerprint, ":"), 32)
+	assert.Contains(t, seen.DNSNames, "example.com")
+
+	s := c.GetServer("internal")
+	assert.Equal(t, PublicKeyPin(server.Certificate()), s.PinnedPublicKey)
+	assert.True(t, strings.HasPrefix(s.PinnedPublicKey, "sha256/"))
+	assert.Nil(t, validateCertificates([]byte(s.CACertificate)))
+}
+
+func TestCreateProfileWithTrustDeclined(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	c := NewEmpty()
+	err := c.CreateProfileWithTrust("internal", server.URL, func(TrustInfo) (bool, error) {
+		return false, nil
+	})
+	assert.Equal(t, ErrServerNotTrusted, err)
+	assert.Empty(t, c.GetServer("internal").CACertificate)
+	assert.Empty(t, c.GetServer("internal").PinnedPublicKey)
+}
+
+func TestTrustServerSystemTrustedClearsPin(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	c := NewEmpty()
+	require.NoError(t, c.CreateProfileWithTrust("internal", server.URL, func(TrustInfo) (bool, error) {
+		return true, nil
+	}))
+	require.NotEmpty(t, c.GetServer("internal").PinnedPublicKey)
+
+	// the server now has a certificate trusted by the system
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	systemRoots = roots
+	t.Cleanup(func() { systemRoots = nil })
+
+	require.NoError(t, c.TrustServer("internal", func(TrustInfo) (bool, error) {
+		t.Fatal("should not prompt for a system trusted server")
+		return false, nil
+	}))
+	assert.Empty(t, c.GetServer("internal").PinnedPublicKey)
+}
+
+func TestTrustServerPlainHTTP(t *testing.T) {
+	c := NewEmpty()
+	require.NoError(t, c.CreateProfileWithTrust("plain", "http://wayfinder.example.com", func(TrustInfo) (bool, error) {
+		t.Fatal("should not prompt for a plain http server")
+		return false, nil
+	}))
+}
@@ -39,8 +39,26 @@ type Config struct {
 	Profiles map[string]*Profile `json:"profiles,omitempty" yaml:"profiles,omitempty"`
 	// Servers is a collection of api endpoints
 	Servers map[string]*Server `json:"servers,omitempty" yaml:"servers,omitempty"`
-	// Version is the version of the configuration
+	// SchemaVersion is the version of the configuration file format
+	SchemaVersion int `json:"schemaVersion,omitempty" yaml:"schemaVersion,omitempty"`
+	// Version is the version of the client which last wrote the configuration
 	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+
+	// persisted is a copy of the configuration as last read from or written to disk
+	persisted *Config
+	// sources are the files the configuration was loaded from, in order of precedence
+	sources []string
+	// origins records the file each profile, server and user was loaded from
+	origins map[string]string
+	// files holds the contents of each source file as last read or written
+	files map[string]*Config
+	// migrated indicates the configuration was read with an older schema and migrated in memory
+	migrated bool
+	// migratedFiles are the source files read with an older schema, which are backed up before
+	// the migration is first written to them
+	migratedFiles map[string]bool
+	// overrides take precedence over the configuration, and are never persisted
+	overrides Overrides
 }
 
 // AuthInfo defines a credential to the api endpoint
@@ -49,6 +67,56 @@ type AuthInfo struct {
 	Identity *Identity `json:"identity,omitempty" yaml:"identity,omitempty"`
 	// Token is a static token to use
 	Token *string `json:"token,omitempty" yaml:"token,omitempty"`
+	// Exec is an external command which provides the token to use
+	Exec *ExecConfig `json:"exec,omitempty" yaml:"exec,omitempty"`
+	// ServiceAccount uses the projected token of the Kubernetes service account the client runs as
+	ServiceAccount *ServiceAccountConfig `json:"serviceAccount,omitempty" yaml:"serviceAccount,omitempty"`
+}
+
+// DefaultServiceAccountTokenPath is the default path of the projected service account token
+const DefaultServiceAccountTokenPath = "/var/run/secrets/tokens/wayfinder"
+
+// ServiceAccountConfig defines authentication with a projected Kubernetes service account token,
+// which is re-read as the kubelet rotates it
+type ServiceAccountConfig struct {
+	// TokenPath is the path of the projected token, defaulting to DefaultServiceAccountTokenPath
+	TokenPath string `json:"tokenPath,omitempty" yaml:"tokenPath,omitempty"`
+	// Exchange exchanges the service account token for a Wayfinder token, for servers which do
+	// not accept service account tokens directly
+	Exchange bool `json:"exchange,omitempty" yaml:"exchange,omitempty"`
+}
+
+// GetTokenPath returns the path of the projected token
+func (s *ServiceAccountConfig) GetTokenPath() string {
+	if s.TokenPath == "" {
+		return DefaultServiceAccountTokenPath
+	}
+
+	return s.TokenPath
+}
+
+// ExecAPIVersion is the version of the exec credential protocol supported by the client
+const ExecAPIVersion = "wayfinder.appvia.io/v1"
+
+// ExecConfig defines an external command which writes a token to stdout as a JSON IssuedToken,
+// for example {"token": "...", "expires": 1700000000}. The token is cached until it expires.
+type ExecConfig struct {
+	// APIVersion is the version of the exec credential protocol the command speaks
+	APIVersion string `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	// Command is the command to run
+	Command string `json:"command,omitempty" yaml:"command,omitempty"`
+	// Args are the arguments to pass to the command
+	Args []string `json:"args,omitempty" yaml:"args,omitempty"`
+	// Env are additional environment variables to set for the command
+	Env []ExecEnvVar `json:"env,omitempty" yaml:"env,omitempty"`
+}
+
+// ExecEnvVar is an environment variable to set for an exec credential command
+type ExecEnvVar struct {
+	// Name is the name of the variable
+	Name string `json:"name" yaml:"name"`
+	// Value is the value of the variable
+	Value string `json:"value" yaml:"value"`
 }
 
 // Identity is a wayfinder manage identity
@@ -75,8 +143,27 @@ type Server struct {
 	Endpoint string `json:"server,omitempty" yaml:"server,omitempty"`
 	// CACertificate is the ca bundle used to verify a self-signed api
 	CACertificate string `json:"caCertificate,omitempty" yaml:"caCertificate,omitempty"`
+	// PinnedPublicKey is the pin of the server public key trusted on first use, as returned by
+	// PublicKeyPin. Connections to a server presenting a different key are refused.
+	PinnedPublicKey string `json:"pinnedPublicKey,omitempty" yaml:"pinnedPublicKey,omitempty"`
+	// ClientCertificate is the PEM-encoded client certificate to present to the server, or the path
+	// to a file holding it, for servers requiring mutual TLS
+	ClientCertificate string `json:"clientCertificate,omitempty" yaml:"clientCertificate,omitempty"`
+	// ClientKey is the PEM-encoded private key of the client certificate, or the path to a file
+	// holding it
+	ClientKey string `json:"clientKey,omitempty" yaml:"clientKey,omitempty"`
+	// InsecureSkipTLSVerify disables verification of the server certificate
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty" yaml:"insecureSkipTLSVerify,omitempty"`
+	// TLSServerName is the name to use for SNI and to verify the server certificate against, where
+	// it differs from the host of the endpoint
+	TLSServerName string `json:"tlsServerName,omitempty" yaml:"tlsServerName,omitempty"`
+	// ProxyURL is the url of a proxy to use to reach the server, overriding the proxy environment
+	ProxyURL string `json:"proxyURL,omitempty" yaml:"proxyURL,omitempty"`
+	// NoProxy is a comma-separated list of hosts, domains and CIDRs which should not be proxied,
+	// overriding the NO_PROXY environment variable
+	NoProxy string `json:"noProxy,omitempty" yaml:"noProxy,omitempty"`
 	// APIInfo is a set of metadata about this instance of Wayfinder
-	APIInfo *APIInfo `json:"apiInfo,omitempty"`
+	APIInfo *APIInfo `json:"apiInfo,omitempty" yaml:"apiInfo,omitempty"`
 }
 
 const defaultAPIBase = "/api/v2"
@@ -101,9 +188,9 @@ func (s *Server) GetAPIInfo() APIInfo {
 type APIInfo struct {
 	// NonResourceAPI is the base path for the non-resource API (i.e. our non-CRD API endpoints such
 	// as login)
-	NonResourceAPI string `json:"nonResourceAPI,omitempty"`
+	NonResourceAPI string `json:"nonResourceAPI,omitempty" yaml:"nonResourceAPI,omitempty"`
 	// ResourceAPI is the base path for the resource API (i.e. access to our CRDs)
-	ResourceAPI string `json:"resourceAPI,omitempty"`
+	ResourceAPI string `json:"resourceAPI,omitempty" yaml:"resourceAPI,omitempty"`
 	// KubeProxyAPI is the base path for the kube proxy API (i.e. access to managed clusters)
-	KubeProxyAPI string `json:"kubeProxyAPI,omitempty"`
+	KubeProxyAPI string `json:"kubeProxyAPI,omitempty" yaml:"kubeProxyAPI,omitempty"`
 }
@@ -0,0 +1,199 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+
+	"github.com/appvia/wfclient/pkg/authtypes"
+	jwsutils "github.com/appvia/wfclient/pkg/utils/jwt"
+	"github.com/appvia/wfclient/pkg/utils/validation"
+)
+
+// Validate checks the configuration for problems, returning a validation error describing each
+// one found, or nil if there are none. Problems which will not stop the configuration being used,
+// such as expired tokens, are reported as warnings.
+func (c *Config) Validate() *validation.Error {
+	verr := validation.NewError("client configuration is invalid")
+
+	if c.CurrentProfile != "" && !c.HasProfile(c.CurrentProfile) {
+		verr.AddFieldErrorf("current-profile", validation.MustExist, "profile %q does not exist", c.CurrentProfile)
+	}
+
+	for _, name := range sortedKeys(c.Profiles) {
+		c.validateProfile(verr, name, c.Profiles[name])
+	}
+	for _, name := range sortedKeys(c.Servers) {
+		validateServer(verr, "servers."+name, c.Servers[name])
+	}
+	for _, name := range sortedKeys(c.AuthInfos) {
+		validateAuthInfo(verr, "users."+name, c.AuthInfos[name])
+	}
+
+	if !verr.HasErrors() {
+		return nil
+	}
+
+	return verr
+}
+
+func (c *Config) validateProfile(verr *validation.Error, name string, p *Profile) {
+	field := "profiles." + name
+	if p == nil {
+		verr.AddFieldError(field, validation.Required, "profile is empty")
+		return
+	}
+
+	switch {
+	case p.Server == "":
+		verr.AddFieldError(field+".server", validation.Required, "profile does not reference a server")
+	case !c.HasServer(p.Server):
+		verr.AddFieldErrorf(field+".server", validation.MustExist, "server %q does not exist", p.Server)
+	}
+
+	switch {
+	case p.AuthInfo == "":
+		verr.AddFieldError(field+".user", validation.Required, "profile does not reference a user")
+	case !c.HasAuthInfo(p.AuthInfo):
+		verr.AddFieldErrorf(field+".user", validation.MustExist, "user %q does not exist", p.AuthInfo)
+	default:
+		if a := c.AuthInfos[p.AuthInfo]; a == nil || (a.Token == nil && a.Identity == nil && a.Exec == nil && a.ServiceAccount == nil) {
+			verr.AddFieldErrorf(field+".user", validation.Required, "user %q has no authentication configured", p.AuthInfo)
+		}
+	}
+}
+
+func validateServer(verr *validation.Error, field string, s *Server) {
+	if s == nil {
+		verr.AddFieldError(field, validation.Required, "server is empty")
+		return
+	}
+
+	if s.Endpoint == "" {
+		verr.AddFieldError(field+".server", validation.Required, "server endpoint is not set")
+	} else if u, err := url.Parse(s.Endpoint); err != nil {
+		verr.AddFieldErrorf(field+".server", validation.InvalidValue, "endpoint is not a valid url: %s", err)
+	} else if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		verr.AddFieldErrorf(field+".server", validation.InvalidValue, "endpoint %q must be an http or https url", s.Endpoint)
+	}
+
+	if s.ProxyURL != "" {
+		if u, err := url.Parse(s.ProxyURL); err != nil || u.Host == "" {
+			verr.AddFieldErrorf(field+".proxyURL", validation.InvalidValue, "proxy %q is not a valid url", s.ProxyURL)
+		}
+	}
+	if s.HasClientCertificate() {
+		if _, err := s.LoadClientCertificate(); err != nil {
+			verr.AddFieldErrorf(field+".clientCertificate", validation.InvalidValue, "%s", err)
+		}
+	}
+	if s.InsecureSkipTLSVerify {
+		verr.AddFieldError(field+".insecureSkipTLSVerify", validation.FieldWarning, "server certificate will not be verified")
+	}
+
+	if s.CACertificate != "" {
+		if err := validateCertificates([]byte(s.CACertificate)); err != nil {
+			verr.AddFieldErrorf(field+".caCertificate", validation.InvalidValue, "certificate authority is invalid: %s", err)
+		}
+	}
+}
+
+// validateCertificates checks the data holds one or more PEM-encoded certificates
+func validateCertificates(data []byte) error {
+	found := 0
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return fmt.Errorf("unexpected %s block", block.Type)
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return err
+		}
+		found++
+	}
+	if found == 0 {
+		return fmt.Errorf("no PEM encoded certificates found")
+	}
+
+	return nil
+}
+
+func validateAuthInfo(verr *validation.Error, field string, a *AuthInfo) {
+	if a == nil {
+		return
+	}
+
+	if a.Token != nil && *a.Token == "" {
+		verr.AddFieldError(field+".token", validation.Required, "token is empty")
+	}
+
+	if a.Identity != nil {
+		validateJWT(verr, field+".identity.token", a.Identity.Token)
+		validateJWT(verr, field+".identity.refresh-token", a.Identity.RefreshToken)
+
+		if a.Identity.Token == "" && a.Identity.RefreshToken == "" {
+			verr.AddFieldError(field+".identity", validation.Required, "identity has neither a token nor a refresh token")
+		} else if a.Identity.RefreshToken == "" {
+			if expired, err := authtypes.IsTokenExpired(a.Identity.Token); err == nil && expired {
+				verr.AddFieldError(field+".identity.token", validation.FieldWarning, "token has expired and cannot be refreshed, please login again")
+			}
+		}
+	}
+
+	if a.ServiceAccount != nil {
+		if _, err := os.Stat(a.ServiceAccount.GetTokenPath()); err != nil {
+			verr.AddFieldErrorf(field+".serviceAccount.tokenPath", validation.FieldWarning, "service account token cannot be read: %s", err)
+		}
+	}
+
+	if a.Exec != nil {
+		if a.Exec.Command == "" {
+			verr.AddFieldError(field+".exec.command", validation.Required, "exec command is not set")
+		}
+		if a.Exec.APIVersion != "" && a.Exec.APIVersion != ExecAPIVersion {
+			verr.AddFieldErrorf(field+".exec.apiVersion", validation.NotAllowed, "apiVersion %q is not supported, expected %q", a.Exec.APIVersion, ExecAPIVersion)
+		}
+	}
+}
+
+func validateJWT(verr *validation.Error, field, token string) {
+	if token == "" {
+		return
+	}
+	if _, err := jwsutils.NewClaimsFromRawToken(token); err != nil {
+		verr.AddFieldErrorf(field, validation.InvalidValue, "token is not a valid JWT: %s", err)
+	}
+}
+
+func sortedKeys[T any](m map[string]*T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
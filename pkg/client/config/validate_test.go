@@ -0,0 +1,107 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/appvia/wfclient/pkg/utils/validation"
+)
+
+func makeTestJWT(t *testing.T, expires time.Time) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"exp": expires.Unix()}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	return token
+}
+
+func TestValidate(t *testing.T) {
+	c := NewEmpty()
+	c.CurrentProfile = "missing"
+	c.AddProfile("dangling", &Profile{Server: "nope", AuthInfo: "nobody"})
+	c.AddProfile("noauth", &Profile{Server: "bad", AuthInfo: "empty"})
+	c.AddServer("bad", &Server{Endpoint: "wayfinder.example.com", CACertificate: "not a certificate"})
+	c.AddAuthInfo("empty", &AuthInfo{})
+	c.AddAuthInfo("broken", &AuthInfo{Identity: &Identity{Token: "not-a-jwt"}})
+	c.AddAuthInfo("expired", &AuthInfo{Identity: &Identity{Token: makeTestJWT(t, time.Now().Add(-time.Hour))}})
+	c.AddAuthInfo("exec", &AuthInfo{Exec: &ExecConfig{}})
+
+	verr := c.Validate()
+	require.NotNil(t, verr)
+
+	fields := map[string]validation.ErrorCode{}
+	for _, fe := range verr.FieldErrors {
+		fields[fe.Field] = fe.ErrCode
+	}
+	assert.Equal(t, map[string]validation.ErrorCode{
+		"current-profile":              validation.MustExist,
+		"profiles.dangling.server":     validation.MustExist,
+		"profiles.dangling.user":       validation.MustExist,
+		"profiles.noauth.user":         validation.Required,
+		"servers.bad.server":           validation.InvalidValue,
+		"servers.bad.caCertificate":    validation.InvalidValue,
+		"users.broken.identity.token":  validation.InvalidValue,
+		"users.expired.identity.token": validation.FieldWarning,
+		"users.exec.exec.command":      validation.Required,
+	}, fields)
+
+	// warnings alone do not make the configuration invalid
+	err := c.IsValid()
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "users.expired")
+}
+
+func TestValidateValid(t *testing.T) {
+	c := NewEmpty()
+	c.CreateProfile("local", "https://wayfinder.example.com")
+	c.AddAuthInfo("local", &AuthInfo{Identity: &Identity{Token: makeTestJWT(t, time.Now().Add(time.Hour))}})
+	c.CurrentProfile = "local"
+
+	assert.Nil(t, c.Validate())
+}
+
+func TestHasValidProfileMissing(t *testing.T) {
+	c := NewEmpty()
+	assert.Equal(t, ErrNoProfile, c.HasValidProfile("missing"))
+	assert.Equal(t, ErrNoProfileSelected, c.HasValidProfile(""))
+}
+
+func TestGetConfigStrict(t *testing.T) {
+	t.Setenv(EnvWayfinderServer, "not a url")
+	t.Setenv(EnvWayfinderToken, "static")
+
+	_, err := GetConfig()
+	require.NoError(t, err)
+
+	_, err = GetConfig(UseStrictValidation())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "servers.default.server")
+}
+
+func TestValidateClientCertificate(t *testing.T) {
+	c := NewEmpty()
+	c.AddServer("mtls", &Server{Endpoint: "https://wayfinder.example.com", ClientCertificate: "/does/not/exist.crt"})
+
+	verr := c.Validate()
+	require.NotNil(t, verr)
+	assert.True(t, verr.ContainsFieldError("servers.mtls.clientCertificate"))
+}
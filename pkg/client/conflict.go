@@ -0,0 +1,93 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	corev1 "github.com/appvia/wfclient/pkg/apis/core/v1alpha1"
+	"github.com/appvia/wfclient/pkg/utils/retry"
+)
+
+// ConflictBackoff controls how often RetryOnConflict attempts an update
+type ConflictBackoff struct {
+	// Steps is the maximum number of update attempts
+	Steps int
+	// Interval is the initial delay between attempts, growing with each retry
+	Interval time.Duration
+}
+
+// DefaultConflictBackoff is a reasonable backoff for objects edited by both people and automation
+var DefaultConflictBackoff = ConflictBackoff{Steps: 5, Interval: 10 * time.Millisecond}
+
+// MutateFunc applies a change to the latest version of an object
+type MutateFunc func(obj Object) error
+
+// RetryOnConflict retrieves the latest version of the object identified by key into obj, applies
+// mutate to it and updates it. If the update fails because the object has been changed since it
+// was retrieved, the whole cycle is repeated, so mutate must be safe to call more than once. For
+// versioned objects, key.Version selects the version being updated. The type information of obj is
+// taken from Scheme if not set.
+func RetryOnConflict(ctx context.Context, wf WFClient, key ObjectKey, obj Object, mutate MutateFunc, backoff ConflictBackoff, opts ...UpdateOption) error {
+	if corev1.IsVersioned(obj) && key.Version == "" {
+		return fmt.Errorf("version must be set on the key of %s to update", key.Name)
+	}
+	if backoff.Steps <= 0 {
+		backoff = DefaultConflictBackoff
+	}
+	// obj may be empty, so take its type information from the client scheme if not set
+	if gvk := obj.GetObjectKind().GroupVersionKind(); gvk.Group == "" || gvk.Version == "" {
+		if gvks, _, err := Scheme.ObjectKinds(obj); err == nil && len(gvks) > 0 {
+			obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+		}
+	}
+	// we handle the conflicts ourselves, rather than only the status-only ones
+	opts = append(append([]UpdateOption{}, opts...), WithNoRetryOnConflict(true))
+
+	var lastErr error
+	err := retry.Retry(ctx, backoff.Steps, true, backoff.Interval, func() (bool, error) {
+		// retrieve into a fresh object so no stale fields survive from the previous attempt
+		latest := newObjectOf(obj)
+		if err := wf.Get(ctx, key, latest); err != nil {
+			return false, err
+		}
+		reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(latest).Elem())
+
+		if err := mutate(obj); err != nil {
+			return false, err
+		}
+
+		if err := wf.Update(ctx, obj, opts...); err != nil {
+			if !IsConflict(err) {
+				return false, err
+			}
+			lastErr = err
+
+			return false, nil
+		}
+
+		return true, nil
+	})
+	if retry.IsRetryFailed(err) && lastErr != nil {
+		return fmt.Errorf("gave up updating %s after %d conflicts: %w", key, backoff.Steps, lastErr)
+	}
+
+	return err
+}
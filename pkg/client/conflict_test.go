@@ -0,0 +1,109 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appv2beta1 "github.com/appvia/wfclient/pkg/apis/app/v2beta1"
+)
+
+func TestRetryOnConflict(t *testing.T) {
+	live := newTestAppEnv("shop", "dev")
+	live.ResourceVersion = "1"
+	live.Spec.Stage = "dev"
+	updates := 0
+
+	wf := newTestDeleteClient(t, func(req *http.Request) (int, interface{}) {
+		switch req.Method {
+		case http.MethodGet:
+			return http.StatusOK, live
+		case http.MethodPut:
+			updated := &appv2beta1.AppEnv{}
+			require.NoError(t, json.NewDecoder(req.Body).Decode(updated))
+			updates++
+			if updates == 1 {
+				// someone else changes the object before our first update lands
+				live.ResourceVersion = "2"
+				live.Spec.Namespace = "changed-by-someone-else"
+				return http.StatusConflict, APIError{Code: http.StatusConflict, Reason: "Conflict"}
+			}
+			assert.Equal(t, "2", updated.ResourceVersion)
+			return http.StatusOK, updated
+		}
+		return http.StatusNotImplemented, nil
+	})
+
+	env := &appv2beta1.AppEnv{}
+	err := RetryOnConflict(context.Background(), wf, ObjectKeyFromObject(live), env, func(obj Object) error {
+		obj.(*appv2beta1.AppEnv).Spec.Stage = "prod"
+		return nil
+	}, ConflictBackoff{Steps: 3, Interval: time.Millisecond})
+	require.NoError(t, err)
+	assert.Equal(t, 2, updates)
+	assert.Equal(t, "prod", env.Spec.Stage)
+	assert.Equal(t, "changed-by-someone-else", env.Spec.Namespace)
+}
+
+func TestRetryOnConflictGivesUp(t *testing.T) {
+	live := newTestAppEnv("shop", "dev")
+	wf := newTestDeleteClient(t, func(req *http.Request) (int, interface{}) {
+		if req.Method == http.MethodGet {
+			return http.StatusOK, live
+		}
+		return http.StatusConflict, APIError{Code: http.StatusConflict, Reason: "Conflict"}
+	})
+
+	err := RetryOnConflict(context.Background(), wf, ObjectKeyFromObject(live), &appv2beta1.AppEnv{}, func(Object) error {
+		return nil
+	}, ConflictBackoff{Steps: 2, Interval: time.Millisecond})
+	require.Error(t, err)
+	assert.True(t, IsConflict(err))
+}
+
+func TestRetryOnConflictMutateError(t *testing.T) {
+	live := newTestAppEnv("shop", "dev")
+	wf := newTestDeleteClient(t, func(req *http.Request) (int, interface{}) {
+		return http.StatusOK, live
+	})
+
+	bad := errors.New("bad mutation")
+	err := RetryOnConflict(context.Background(), wf, ObjectKeyFromObject(live), &appv2beta1.AppEnv{}, func(Object) error {
+		return bad
+	}, DefaultConflictBackoff)
+	assert.Equal(t, bad, err)
+}
+
+func TestRetryOnConflictVersionedRequiresVersion(t *testing.T) {
+	wf := newTestDeleteClient(t, func(req *http.Request) (int, interface{}) {
+		return http.StatusOK, nil
+	})
+
+	err := RetryOnConflict(context.Background(), wf, ObjectKey{Workspace: "demo", Name: "shop"}, &appv2beta1.AppDefinition{}, func(Object) error {
+		return nil
+	}, DefaultConflictBackoff)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "version must be set")
+}
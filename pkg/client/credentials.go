@@ -0,0 +1,223 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/appvia/wfclient/pkg/authtypes"
+	"github.com/appvia/wfclient/pkg/client/config"
+	"github.com/appvia/wfclient/pkg/utils"
+	jwsutils "github.com/appvia/wfclient/pkg/utils/jwt"
+)
+
+const (
+	// TokenKindRefresh is a refresh token issued on login, exchanged for API tokens
+	TokenKindRefresh = "refresh"
+	// TokenKindExchange is an access token which is exchanged for API tokens
+	TokenKindExchange = "exchange"
+	// TokenKindAccess is an API token issued for an access token
+	TokenKindAccess = "access"
+	// TokenKindKubernetes is a Kubernetes service account token
+	TokenKindKubernetes = "kubernetes"
+	// TokenKindAPI is an API token issued to a user
+	TokenKindAPI = "api"
+	// TokenKindOpaque is a token which is not a JWT, so cannot be inspected
+	TokenKindOpaque = "opaque"
+)
+
+// CredentialsDescription describes the credentials a profile authenticates with
+type CredentialsDescription struct {
+	// Profile is the name of the profile
+	Profile string `json:"profile"`
+	// Server is the endpoint of the server of the profile
+	Server string `json:"server,omitempty"`
+	// AuthInfo is the name of the user of the profile
+	AuthInfo string `json:"user,omitempty"`
+	// Method is the authentication method, as returned by GetProfileAuthMethod
+	Method string `json:"method"`
+	// Source is the file or command the token is read from, where it is not stored in the profile
+	Source string `json:"source,omitempty"`
+	// Tokens describe each of the tokens held for the profile
+	Tokens []TokenDescription `json:"tokens,omitempty"`
+}
+
+// TokenDescription describes a single token, without revealing its value
+type TokenDescription struct {
+	// Field is where the token is held
+	Field string `json:"field"`
+	// Kind is the kind of token, one of the TokenKind constants
+	Kind string `json:"kind"`
+	// Value is the redacted value of the token
+	Value string `json:"value"`
+	// Subject is the subject the token was issued to
+	Subject string `json:"subject,omitempty"`
+	// Email is the email of the subject, if included in the token
+	Email string `json:"email,omitempty"`
+	// Issuer is the issuer of the token
+	Issuer string `json:"issuer,omitempty"`
+	// Audience is the audience of the token
+	Audience []string `json:"audience,omitempty"`
+	// Scopes are the scopes granted to the token
+	Scopes []string `json:"scopes,omitempty"`
+	// IssuedAt is when the token was issued
+	IssuedAt *time.Time `json:"issuedAt,omitempty"`
+	// Expires is when the token expires
+	Expires *time.Time `json:"expires,omitempty"`
+	// Remaining is the time remaining until the token expires
+	Remaining string `json:"remaining,omitempty"`
+	// Expired indicates the token has expired
+	Expired bool `json:"expired,omitempty"`
+	// Error describes why the token could not be read or inspected
+	Error string `json:"error,omitempty"`
+}
+
+// DescribeCredentials inspects the credentials of the profile, or the current profile if empty,
+// describing each token held without revealing their values. Exec credential commands are not
+// run, as they may prompt the user or have other side effects.
+func DescribeCredentials(cfg *config.Config, profile string) (*CredentialsDescription, error) {
+	if profile == "" {
+		profile = cfg.GetCurrentProfile()
+	}
+	if err := cfg.HasValidProfile(profile); err != nil {
+		return nil, err
+	}
+
+	p := cfg.GetProfile(profile)
+	desc := &CredentialsDescription{
+		Profile:  profile,
+		AuthInfo: p.AuthInfo,
+		Method:   cfg.GetProfileAuthMethod(profile),
+	}
+	if server := cfg.GetEffectiveServer(profile); server != nil {
+		desc.Server = server.Endpoint
+	}
+
+	// @step: a token file override replaces the credentials of the profile
+	if file := cfg.GetOverrides().TokenFile; file != "" {
+		desc.Method = "tokenFile"
+		desc.Source = file
+		desc.Tokens = append(desc.Tokens, describeTokenFile("tokenFile", file))
+
+		return desc, nil
+	}
+
+	auth := cfg.AuthInfos[p.AuthInfo]
+	switch {
+	case auth == nil:
+	case auth.Token != nil:
+		desc.Tokens = append(desc.Tokens, DescribeToken("token", *auth.Token))
+	case auth.Identity != nil:
+		if auth.Identity.RefreshToken != "" {
+			desc.Tokens = append(desc.Tokens, DescribeToken("identity.refresh-token", auth.Identity.RefreshToken))
+		}
+		if auth.Identity.Token != "" {
+			desc.Tokens = append(desc.Tokens, DescribeToken("identity.token", auth.Identity.Token))
+		}
+	case auth.Exec != nil:
+		desc.Source = auth.Exec.Command
+	case auth.ServiceAccount != nil:
+		desc.Source = auth.ServiceAccount.GetTokenPath()
+		desc.Tokens = append(desc.Tokens, describeTokenFile("serviceAccount", desc.Source))
+	}
+
+	return desc, nil
+}
+
+// describeTokenFile describes the token held in a file
+func describeTokenFile(field, path string) TokenDescription {
+	token, err := ReadTokenFile(path)
+	if err != nil {
+		return TokenDescription{Field: field, Kind: TokenKindOpaque, Error: err.Error()}
+	}
+
+	return DescribeToken(field, token)
+}
+
+// DescribeToken inspects the claims of a token without verifying it, redacting its value
+func DescribeToken(field, token string) TokenDescription {
+	desc := TokenDescription{Field: field, Kind: TokenKindOpaque, Value: RedactToken(token)}
+
+	claims, err := jwsutils.NewClaimsFromRawToken(token)
+	if err != nil {
+		desc.Error = fmt.Sprintf("token is not a JWT: %s", err)
+		return desc
+	}
+
+	desc.Subject, _ = claims.GetSubject()
+	desc.Email, _ = claims.GetEmail()
+	desc.Issuer, _ = claims.GetIssuer()
+	desc.Scopes, _ = claims.GetScopes()
+	sort.Strings(desc.Scopes)
+	if aud, found := claims.GetAudience(); found {
+		desc.Audience = []string{aud}
+	} else {
+		desc.Audience, _ = claims.GetStringSlice("aud")
+	}
+	desc.Kind = tokenKind(claims, desc.Audience)
+
+	if iat, found := claims.GetFloat64("iat"); found {
+		issued := time.Unix(int64(iat), 0).UTC()
+		desc.IssuedAt = &issued
+	}
+	if expiry, found := claims.GetExpiry(); found {
+		expiry = expiry.UTC()
+		desc.Expires = &expiry
+		desc.Expired = claims.HasExpired()
+		if !desc.Expired {
+			desc.Remaining = time.Until(expiry).Round(time.Second).String()
+		}
+	}
+
+	return desc
+}
+
+// tokenKind determines the kind of token from its scopes and audience
+func tokenKind(claims *jwsutils.Claims, audience []string) string {
+	scopes, _ := claims.GetScopes()
+
+	switch {
+	case authtypes.IsExchangeScoped(claims):
+		return TokenKindExchange
+	case authtypes.IsAccessTokenScoped(claims):
+		return TokenKindAccess
+	case utils.Contains(authtypes.ScopeRefresh, scopes) || utils.Contains(authtypes.ScopeCookieRefresh, scopes) ||
+		utils.Contains(authtypes.RefreshTokenAudience, audience):
+		return TokenKindRefresh
+	case utils.Contains(authtypes.ScopeKubernetesAccount, scopes):
+		return TokenKindKubernetes
+	}
+
+	// @step: projected service account tokens carry the details of the pod in a kubernetes.io claim
+	if _, found := claims.RawClaims()["kubernetes.io"]; found {
+		return TokenKindKubernetes
+	}
+
+	return TokenKindAPI
+}
+
+// RedactToken returns a form of the token which identifies it without revealing it, keeping only
+// the first and last few characters
+func RedactToken(token string) string {
+	if len(token) <= 16 {
+		return "[redacted]"
+	}
+
+	return fmt.Sprintf("%s...%s", token[:6], token[len(token)-4:])
+}
@@ -0,0 +1,116 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/appvia/wfclient/pkg/authtypes"
+	"github.com/appvia/wfclient/pkg/client/config"
+)
+
+func TestDescribeCredentialsIdentity(t *testing.T) {
+	now := time.Now()
+	refresh, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":    "jane",
+		"email":  "jane@example.com",
+		"aud":    authtypes.RefreshTokenAudience,
+		"scopes": []string{authtypes.ScopeRefresh},
+		"iat":    now.Unix(),
+		"exp":    now.Add(24 * time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":    "jane",
+		"aud":    []string{authtypes.Audience, authtypes.KubernetesAudience},
+		"scopes": []string{authtypes.ScopeUser},
+		"exp":    now.Add(-time.Minute).Unix(),
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	cfg := config.NewEmpty()
+	cfg.CreateProfile("dev", "https://wayfinder.example.com")
+	cfg.AddAuthInfo("dev", &config.AuthInfo{Identity: &config.Identity{RefreshToken: refresh, Token: token}})
+	cfg.CurrentProfile = "dev"
+
+	desc, err := DescribeCredentials(cfg, "")
+	require.NoError(t, err)
+	assert.Equal(t, "dev", desc.Profile)
+	assert.Equal(t, "idtoken", desc.Method)
+	assert.Equal(t, "https://wayfinder.example.com", desc.Server)
+	require.Len(t, desc.Tokens, 2)
+
+	r := desc.Tokens[0]
+	assert.Equal(t, "identity.refresh-token", r.Field)
+	assert.Equal(t, TokenKindRefresh, r.Kind)
+	assert.Equal(t, "jane", r.Subject)
+	assert.Equal(t, "jane@example.com", r.Email)
+	assert.Equal(t, []string{authtypes.RefreshTokenAudience}, r.Audience)
+	require.NotNil(t, r.IssuedAt)
+	require.NotNil(t, r.Expires)
+	assert.False(t, r.Expired)
+	remaining, err := time.ParseDuration(r.Remaining)
+	require.NoError(t, err)
+	assert.InDelta(t, 24*time.Hour, remaining, float64(5*time.Second))
+	assert.NotContains(t, r.Value, refresh[20:len(refresh)-4])
+
+	a := desc.Tokens[1]
+	assert.Equal(t, TokenKindAPI, a.Kind)
+	assert.Equal(t, []string{authtypes.Audience, authtypes.KubernetesAudience}, a.Audience)
+	assert.True(t, a.Expired)
+	assert.Empty(t, a.Remaining)
+}
+
+func TestDescribeCredentialsKinds(t *testing.T) {
+	cfg := config.NewEmpty()
+	cfg.CreateProfile("ci", "https://wayfinder.example.com")
+	exchange := makeTestScopedJWT(t, authtypes.ScopeExchange)
+	cfg.AddAuthInfo("ci", &config.AuthInfo{Identity: &config.Identity{RefreshToken: exchange, Token: makeTestScopedJWT(t, authtypes.ScopeAccessToken)}})
+
+	desc, err := DescribeCredentials(cfg, "ci")
+	require.NoError(t, err)
+	require.Len(t, desc.Tokens, 2)
+	assert.Equal(t, TokenKindExchange, desc.Tokens[0].Kind)
+	assert.Equal(t, TokenKindAccess, desc.Tokens[1].Kind)
+
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("not-a-jwt-but-a-long-opaque-token"), 0600))
+	cfg.SetOverrides(config.Overrides{TokenFile: path})
+
+	desc, err = DescribeCredentials(cfg, "ci")
+	require.NoError(t, err)
+	assert.Equal(t, path, desc.Source)
+	require.Len(t, desc.Tokens, 1)
+	assert.Equal(t, TokenKindOpaque, desc.Tokens[0].Kind)
+	assert.Equal(t, "not-a-...oken", desc.Tokens[0].Value)
+	assert.NotEmpty(t, desc.Tokens[0].Error)
+
+	_, err = DescribeCredentials(cfg, "missing")
+	assert.Equal(t, config.ErrNoProfile, err)
+}
+
+func TestRedactToken(t *testing.T) {
+	assert.Equal(t, "[redacted]", RedactToken("short"))
+	assert.Equal(t, "abcdef...wxyz", RedactToken("abcdefghijklmnopqrstuvwxyz"))
+}
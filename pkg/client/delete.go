@@ -0,0 +1,218 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "github.com/appvia/wfclient/pkg/apis/core/v1alpha1"
+	"github.com/appvia/wfclient/pkg/common"
+	"github.com/appvia/wfclient/pkg/utils/retry"
+	"github.com/appvia/wfclient/pkg/utils/validation"
+)
+
+var (
+	// deletionPollInterval is how often we check on an object being deleted
+	deletionPollInterval = 2 * time.Second
+	// DefaultDependentDeletionTimeout is how long DeleteWithDependents waits for each dependent to
+	// be removed if no WithWaitForDeletion option is provided
+	DefaultDependentDeletionTimeout = 10 * time.Minute
+)
+
+// waitForDeletion polls the object until it no longer exists, reporting status changes along the way
+func (s *wfClient) waitForDeletion(ctx context.Context, obj Object, o DeleteOptions) error {
+	key := ObjectKeyFromObject(obj)
+	var last corev1.CommonStatus
+
+	err := retry.WaitUntilComplete(ctx, o.WaitForDeletion, deletionPollInterval, func() (bool, error) {
+		current := newObjectOf(obj)
+		if err := s.Get(ctx, key, current); err != nil {
+			if IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		}
+
+		status := current.GetCommonStatus()
+		if status == nil {
+			return false, nil
+		}
+		if status.Status != last.Status || status.Message != last.Message || status.Detail != last.Detail {
+			last = *status
+			common.Log(ctx).WithFields(map[string]interface{}{
+				"object": key.String(),
+				"status": status.Status,
+				"detail": status.Detail,
+			}).Debug("waiting for object deletion")
+
+			if o.DeletionStatusHandler != nil {
+				o.DeletionStatusHandler(ctx, current, *status)
+			}
+		}
+		if status.Status == corev1.DeleteFailedStatus {
+			return false, fmt.Errorf("deletion of %s failed: %s", key, statusDescription(*status))
+		}
+
+		return false, nil
+	})
+	if retry.IsRetryFailed(err) {
+		if last.Status != "" {
+			return fmt.Errorf("timed out waiting for %s to be deleted, last status %s", key, statusDescription(last))
+		}
+		return fmt.Errorf("timed out waiting for %s to be deleted", key)
+	}
+
+	return err
+}
+
+func statusDescription(s corev1.CommonStatus) string {
+	desc := string(s.Status)
+	if s.Message != "" {
+		desc += ": " + s.Message
+	}
+	if s.Detail != "" {
+		desc += " (" + s.Detail + ")"
+	}
+	return desc
+}
+
+// DependencyTree describes an object and the objects which must be deleted before it
+type DependencyTree struct {
+	// Object is the object to be deleted
+	Object Object
+	// Dependents are the non-system objects which depend on this object
+	Dependents []*DependencyTree
+}
+
+// String renders the tree with each dependent indented below the object depending on it
+func (t *DependencyTree) String() string {
+	sb := &strings.Builder{}
+	t.write(sb, 0)
+	return sb.String()
+}
+
+func (t *DependencyTree) write(sb *strings.Builder, depth int) {
+	sb.WriteString(strings.Repeat("  ", depth))
+	sb.WriteString(dependencyTreeName(t.Object))
+	sb.WriteString("\n")
+	for _, d := range t.Dependents {
+		d.write(sb, depth+1)
+	}
+}
+
+func dependencyTreeName(obj Object) string {
+	ref := validation.DependentReference{
+		Kind:      objectKind(obj),
+		Name:      corev1.GetVersionedObjectName(obj),
+		Version:   corev1.GetVersion(obj),
+		Workspace: corev1.Workspace(obj),
+	}
+	return ref.String()
+}
+
+// DeleteWithDependents deletes the object along with every (non-system) object which depends on
+// it. The dependents are discovered from the dependency violations reported by the API, and are
+// deleted in reverse-dependency order, waiting for each to be removed before moving on, with the
+// target object deleted last. With WithDryRun, nothing is deleted and the returned tree describes
+// what would be deleted. Dependents must be of a kind registered with Scheme.
+func DeleteWithDependents(ctx context.Context, wf WFClient, obj Object, opts ...DeleteOption) (*DependencyTree, error) {
+	o := GetDeleteOptions(opts)
+
+	tree, err := buildDependencyTree(ctx, wf, obj, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+	if o.DryRun {
+		return tree, nil
+	}
+
+	// dependents must be removed before their dependencies can be deleted
+	dependentOpts := append([]DeleteOption{}, opts...)
+	if o.WaitForDeletion == 0 {
+		dependentOpts = append(dependentOpts, WithWaitForDeletion(DefaultDependentDeletionTimeout))
+	}
+
+	return tree, deleteDependencyTree(ctx, wf, tree, true, opts, dependentOpts)
+}
+
+// buildDependencyTree discovers the dependents of the object with a dry-run delete
+func buildDependencyTree(ctx context.Context, wf WFClient, obj Object, visited map[string]bool) (*DependencyTree, error) {
+	name := dependencyTreeName(obj)
+	if visited[name] {
+		return nil, fmt.Errorf("dependency cycle detected at %s", name)
+	}
+	visited[name] = true
+	defer delete(visited, name)
+
+	tree := &DependencyTree{Object: obj}
+
+	err := wf.Delete(ctx, obj.Clone(), WithDryRun(true))
+	if err == nil {
+		return tree, nil
+	}
+	apiErr, ok := asAPIError(err)
+	if !ok || apiErr.DependencyViolation == nil {
+		return nil, fmt.Errorf("failed to check dependents of %s: %w", name, err)
+	}
+
+	for _, ref := range apiErr.DependencyViolation.Dependents {
+		if ref.System {
+			continue
+		}
+		dep, err := NewObjectForKind(ref.Kind)
+		if err != nil {
+			return nil, fmt.Errorf("cannot delete dependent %s: %w", ref, err)
+		}
+		dep.SetName(ref.Name)
+		dep.SetNamespace(ref.Workspace.Namespace())
+		if v, ok := dep.(corev1.Versioned); ok {
+			v.SetVersion(ref.Version)
+		}
+
+		child, err := buildDependencyTree(ctx, wf, dep, visited)
+		if err != nil {
+			return nil, err
+		}
+		tree.Dependents = append(tree.Dependents, child)
+	}
+
+	return tree, nil
+}
+
+// deleteDependencyTree deletes the dependents of the tree depth-first, then the tree's object
+func deleteDependencyTree(ctx context.Context, wf WFClient, tree *DependencyTree, root bool, opts, dependentOpts []DeleteOption) error {
+	for _, d := range tree.Dependents {
+		if err := deleteDependencyTree(ctx, wf, d, false, opts, dependentOpts); err != nil {
+			return err
+		}
+	}
+
+	useOpts := dependentOpts
+	if root {
+		useOpts = opts
+	}
+	common.Log(ctx).WithField("object", dependencyTreeName(tree.Object)).Debug("deleting object")
+
+	if err := wf.Delete(ctx, tree.Object, useOpts...); err != nil && !IsNotFound(err) {
+		return fmt.Errorf("failed to delete %s: %w", dependencyTreeName(tree.Object), err)
+	}
+
+	return nil
+}
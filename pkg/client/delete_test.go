@@ -0,0 +1,140 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "github.com/appvia/wfclient/pkg/apis/core/v1alpha1"
+	"github.com/appvia/wfclient/pkg/client/config"
+	"github.com/appvia/wfclient/pkg/utils/validation"
+)
+
+func newTestDeleteClient(t *testing.T, handler func(req *http.Request) (int, interface{})) WFClient {
+	cfg := config.NewEmpty()
+	cfg.CreateProfile("test", "http://wayfinder.test")
+	cfg.AddAuthInfo("test", &config.AuthInfo{Token: new(string)})
+	cfg.CurrentProfile = "test"
+
+	wf, err := NewWFClient(cfg, useJSONHandler(handler))
+	require.NoError(t, err)
+
+	return wf
+}
+
+// useTestDeletionPollInterval polls quickly for the duration of the test
+func useTestDeletionPollInterval(t *testing.T) {
+	interval := deletionPollInterval
+	t.Cleanup(func() { deletionPollInterval = interval })
+	deletionPollInterval = 10 * time.Millisecond
+}
+
+func TestDeleteWaitForDeletion(t *testing.T) {
+	useTestDeletionPollInterval(t)
+
+	env := newTestAppEnv("shop", "dev")
+	statuses := []corev1.Status{corev1.DeletingStatus, corev1.DeletingStatus, corev1.DeleteActionRequiredStatus}
+	gets := 0
+	wf := newTestDeleteClient(t, func(req *http.Request) (int, interface{}) {
+		if req.Method == http.MethodDelete {
+			return http.StatusOK, env
+		}
+		if gets >= len(statuses) {
+			return http.StatusNotFound, nil
+		}
+		live := newTestAppEnv("shop", "dev")
+		live.Status.Status = statuses[gets]
+		live.Status.Detail = "removing namespace"
+		gets++
+		return http.StatusOK, live
+	})
+
+	var seen []corev1.Status
+	err := wf.Delete(context.Background(), env,
+		WithWaitForDeletion(time.Second),
+		WithDeletionStatusHandler(func(_ context.Context, _ Object, status corev1.CommonStatus) {
+			seen = append(seen, status.Status)
+		}))
+	require.NoError(t, err)
+	assert.Equal(t, []corev1.Status{corev1.DeletingStatus, corev1.DeleteActionRequiredStatus}, seen)
+}
+
+func TestDeleteWaitForDeletionTimeout(t *testing.T) {
+	useTestDeletionPollInterval(t)
+
+	wf := newTestDeleteClient(t, func(req *http.Request) (int, interface{}) {
+		live := newTestAppEnv("shop", "dev")
+		live.Status.Status = corev1.DeleteActionRequiredStatus
+		live.Status.Detail = "cloud resources still exist"
+		return http.StatusOK, live
+	})
+
+	err := wf.Delete(context.Background(), newTestAppEnv("shop", "dev"), WithWaitForDeletion(50*time.Millisecond))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cloud resources still exist")
+}
+
+func TestDeleteWithDependents(t *testing.T) {
+	useTestDeletionPollInterval(t)
+
+	deleted := map[string]bool{}
+	var order []string
+	wf := newTestDeleteClient(t, func(req *http.Request) (int, interface{}) {
+		path := req.URL.Path
+		if req.Method == http.MethodGet {
+			if deleted[path] {
+				return http.StatusNotFound, nil
+			}
+			return http.StatusOK, newTestAppEnv("shop", "dev")
+		}
+		if req.URL.Query().Get("dryRun") != "" {
+			if strings.HasSuffix(path, "/appdeploymentjobs/deploy") {
+				return http.StatusConflict, validation.ErrDependencyViolation{
+					Dependents: []validation.DependentReference{
+						{Kind: "AppEnv", Name: "shop-dev", Workspace: "demo"},
+						{Kind: "Secret", Name: "internal", Workspace: "demo", System: true},
+					},
+				}
+			}
+			return http.StatusOK, nil
+		}
+		order = append(order, path)
+		deleted[path] = true
+		return http.StatusOK, nil
+	})
+
+	job := newTestAppDeploymentJob("deploy", corev1.AppEnvRef{}, corev1.PlanRef{})
+
+	tree, err := DeleteWithDependents(context.Background(), wf, job, WithDryRun(true))
+	require.NoError(t, err)
+	assert.Equal(t, "AppDeploymentJob/demo/deploy\n  AppEnv/demo/shop-dev\n", tree.String())
+	assert.Empty(t, order)
+
+	_, err = DeleteWithDependents(context.Background(), wf, job)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"/resources/app.appvia.io/v2beta1/workspaces/demo/appenvs/shop-dev",
+		"/resources/app.appvia.io/v2beta1/workspaces/demo/appdeploymentjobs/deploy",
+	}, order)
+}
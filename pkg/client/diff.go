@@ -0,0 +1,289 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/appvia/wfclient/pkg/utils/validation"
+)
+
+// DiffOperation is the type of change made to a field
+type DiffOperation string
+
+const (
+	// DiffAdded indicates the field is not present on the live object
+	DiffAdded DiffOperation = "added"
+	// DiffRemoved indicates the field is present on the live object but not the desired object
+	DiffRemoved DiffOperation = "removed"
+	// DiffChanged indicates the field value differs between the live and desired object
+	DiffChanged DiffOperation = "changed"
+)
+
+// FieldDiff describes a change to a single field of an object
+type FieldDiff struct {
+	// Path is the path to the field, in format x.y[0].z
+	Path string `json:"path"`
+	// Operation is the type of change
+	Operation DiffOperation `json:"operation"`
+	// Live is the value currently held by the server, if any
+	Live interface{} `json:"live,omitempty"`
+	// Desired is the value which will be held once the change is applied, if any
+	Desired interface{} `json:"desired,omitempty"`
+}
+
+// DiffResult describes the changes that will be made to an object on the server
+type DiffResult struct {
+	// Key identifies the object
+	Key ObjectKey `json:"-"`
+	// Object is the string representation of Key
+	Object string `json:"object"`
+	// Exists indicates the object already exists on the server
+	Exists bool `json:"exists"`
+	// Changes is the list of field-level changes
+	Changes []FieldDiff `json:"changes"`
+	// Warnings are any warnings returned by the server during the dry run
+	Warnings []validation.Warning `json:"warnings,omitempty"`
+}
+
+// HasChanges returns true if applying the object would change it on the server
+func (d DiffResult) HasChanges() bool {
+	return len(d.Changes) > 0
+}
+
+// JSON renders the diff as JSON
+func (d DiffResult) JSON() ([]byte, error) {
+	if d.Changes == nil {
+		d.Changes = []FieldDiff{}
+	}
+
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// Unified renders the diff as unified-style text, with one hunk per changed field
+func (d DiffResult) Unified() string {
+	sb := &strings.Builder{}
+	live := "live/" + d.Object
+	if !d.Exists {
+		live = "/dev/null"
+	}
+	fmt.Fprintf(sb, "--- %s\n+++ desired/%s\n", live, d.Object)
+
+	for _, c := range d.Changes {
+		fmt.Fprintf(sb, "@@ %s @@\n", c.Path)
+		if c.Operation != DiffAdded {
+			writeDiffLines(sb, "-", c.Live)
+		}
+		if c.Operation != DiffRemoved {
+			writeDiffLines(sb, "+", c.Desired)
+		}
+	}
+	for _, w := range d.Warnings {
+		if msg := w.GetDisplayMessage(); msg != "" {
+			fmt.Fprintf(sb, "# warning: %s\n", msg)
+		}
+	}
+
+	return sb.String()
+}
+
+func writeDiffLines(sb *strings.Builder, prefix string, v interface{}) {
+	encoded, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		encoded = []byte(fmt.Sprintf("%v", v))
+	}
+	for _, line := range strings.Split(string(encoded), "\n") {
+		sb.WriteString(prefix + line + "\n")
+	}
+}
+
+// diffIgnoredFields are the fields managed by the server which are ignored when comparing objects
+var diffIgnoredFields = [][]string{
+	{"status"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "generation"},
+	{"metadata", "managedFields"},
+	{"metadata", "uid"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "selfLink"},
+}
+
+// Diff compares the desired state of an object against the live state on the server. The desired
+// object is run through a server-side dry-run so that defaulting is applied before comparing, so
+// only real changes are reported. The desired object is not modified.
+func Diff(ctx context.Context, wf WFClient, desired Object) (DiffResult, error) {
+	key := ObjectKeyFromObject(desired)
+	result := DiffResult{Key: key, Object: fmt.Sprintf("%s/%s", objectKind(desired), key.String())}
+	if key.Version != "" {
+		result.Object += "@" + key.Version.String()
+	}
+
+	var mu sync.Mutex
+	handler := WithWarningHandler(func(_ context.Context, warnings []validation.Warning) {
+		mu.Lock()
+		defer mu.Unlock()
+		result.Warnings = append(result.Warnings, warnings...)
+	})
+
+	live := newObjectOf(desired)
+	result.Exists = true
+	if err := wf.Get(ctx, key, live); err != nil {
+		if !IsNotFound(err) {
+			return result, err
+		}
+		result.Exists = false
+	}
+
+	defaulted := desired.Clone()
+	if result.Exists {
+		defaulted.SetResourceVersion(live.GetResourceVersion())
+		if err := wf.Update(ctx, defaulted, WithDryRun(true), handler); err != nil {
+			return result, err
+		}
+	} else {
+		if err := wf.Create(ctx, defaulted, WithDryRun(true), handler); err != nil {
+			return result, err
+		}
+	}
+
+	liveDoc := map[string]interface{}{}
+	if result.Exists {
+		var err error
+		if liveDoc, err = toDiffDocument(live); err != nil {
+			return result, err
+		}
+	}
+	desiredDoc, err := toDiffDocument(defaulted)
+	if err != nil {
+		return result, err
+	}
+
+	result.Changes = diffValues("", liveDoc, desiredDoc)
+
+	return result, nil
+}
+
+// newObjectOf returns a new empty object of the same type as obj
+func newObjectOf(obj Object) Object {
+	o := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(Object)
+	o.GetObjectKind().SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+
+	return o
+}
+
+// toDiffDocument converts the object to a generic document with the ignored fields removed
+func toDiffDocument(obj Object) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	doc := map[string]interface{}{}
+	if err := json.Unmarshal(encoded, &doc); err != nil {
+		return nil, err
+	}
+
+	for _, path := range diffIgnoredFields {
+		parent := doc
+		for i, field := range path {
+			if i == len(path)-1 {
+				delete(parent, field)
+				break
+			}
+			next, ok := parent[field].(map[string]interface{})
+			if !ok {
+				break
+			}
+			parent = next
+		}
+	}
+	if meta, ok := doc["metadata"].(map[string]interface{}); ok && len(meta) == 0 {
+		delete(doc, "metadata")
+	}
+
+	return doc, nil
+}
+
+// diffValues returns the field-level differences between the live and desired values
+func diffValues(path string, live, desired interface{}) []FieldDiff {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(l)+len(d))
+		for k := range l {
+			keys = append(keys, k)
+		}
+		for k := range d {
+			if _, found := l[k]; !found {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		var diffs []FieldDiff
+		for _, k := range keys {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			lv, inLive := l[k]
+			dv, inDesired := d[k]
+			switch {
+			case !inLive:
+				diffs = append(diffs, FieldDiff{Path: p, Operation: DiffAdded, Desired: dv})
+			case !inDesired:
+				diffs = append(diffs, FieldDiff{Path: p, Operation: DiffRemoved, Live: lv})
+			default:
+				diffs = append(diffs, diffValues(p, lv, dv)...)
+			}
+		}
+		return diffs
+
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			break
+		}
+		var diffs []FieldDiff
+		for i := 0; i < len(l) || i < len(d); i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(l):
+				diffs = append(diffs, FieldDiff{Path: p, Operation: DiffAdded, Desired: d[i]})
+			case i >= len(d):
+				diffs = append(diffs, FieldDiff{Path: p, Operation: DiffRemoved, Live: l[i]})
+			default:
+				diffs = append(diffs, diffValues(p, l[i], d[i])...)
+			}
+		}
+		return diffs
+	}
+
+	if reflect.DeepEqual(live, desired) {
+		return nil
+	}
+
+	return []FieldDiff{{Path: path, Operation: DiffChanged, Live: live, Desired: desired}}
+}
@@ -0,0 +1,133 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appv2beta1 "github.com/appvia/wfclient/pkg/apis/app/v2beta1"
+	corev1 "github.com/appvia/wfclient/pkg/apis/core/v1alpha1"
+	"github.com/appvia/wfclient/pkg/client/config"
+)
+
+func TestDiffValues(t *testing.T) {
+	live := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"stage": "dev",
+			"cloud": "aws",
+			"vars":  []interface{}{"a", "b"},
+		},
+	}
+	desired := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"stage":     "prod",
+			"namespace": "shop",
+			"vars":      []interface{}{"a"},
+		},
+	}
+
+	assert.Equal(t, []FieldDiff{
+		{Path: "spec.cloud", Operation: DiffRemoved, Live: "aws"},
+		{Path: "spec.namespace", Operation: DiffAdded, Desired: "shop"},
+		{Path: "spec.stage", Operation: DiffChanged, Live: "dev", Desired: "prod"},
+		{Path: "spec.vars[1]", Operation: DiffRemoved, Live: "b"},
+	}, diffValues("", live, desired))
+}
+
+func TestDiffValuesNoChanges(t *testing.T) {
+	doc := map[string]interface{}{"spec": map[string]interface{}{"stage": "dev"}}
+	assert.Empty(t, diffValues("", doc, doc))
+}
+
+func TestDiffResultRenderers(t *testing.T) {
+	d := DiffResult{
+		Object: "AppEnv/demo/shop-dev",
+		Exists: true,
+		Changes: []FieldDiff{
+			{Path: "spec.stage", Operation: DiffChanged, Live: "dev", Desired: "prod"},
+		},
+	}
+	assert.Equal(t, "--- live/AppEnv/demo/shop-dev\n+++ desired/AppEnv/demo/shop-dev\n@@ spec.stage @@\n-\"dev\"\n+\"prod\"\n", d.Unified())
+
+	encoded, err := d.JSON()
+	require.NoError(t, err)
+	decoded := DiffResult{}
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, d.Changes, decoded.Changes)
+}
+
+func TestDiff(t *testing.T) {
+	cfg := config.NewEmpty()
+	cfg.CreateProfile("test", "http://wayfinder.test")
+	cfg.AddAuthInfo("test", &config.AuthInfo{Token: new(string)})
+	cfg.CurrentProfile = "test"
+
+	live := newTestAppEnv("shop", "dev")
+	live.ResourceVersion = "42"
+	live.Generation = 3
+	live.Spec.Stage = "nonprod"
+	live.Spec.Namespace = "shop-dev"
+	live.Status.Status = corev1.SuccessStatus
+
+	var dryRunQuery string
+	wf, err := NewWFClient(cfg, UseRequestDo(func(req *http.Request) (*http.Response, error) {
+		var body []byte
+		header := http.Header{}
+		switch req.Method {
+		case http.MethodGet:
+			body, _ = json.Marshal(live)
+		case http.MethodPut:
+			dryRunQuery = req.URL.RawQuery
+			defaulted := &appv2beta1.AppEnv{}
+			require.NoError(t, json.NewDecoder(req.Body).Decode(defaulted))
+			assert.Equal(t, "42", defaulted.ResourceVersion)
+			// the server defaults the namespace
+			defaulted.Spec.Namespace = "shop-dev"
+			defaulted.ResourceVersion = "43"
+			body, _ = json.Marshal(defaulted)
+			header.Add("warning", `{"warningType":"General","name":"spec.stage","message":"stage is changing"}`)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(string(body))),
+			Request:    req,
+		}, nil
+	}))
+	require.NoError(t, err)
+
+	desired := newTestAppEnv("shop", "dev")
+	desired.Spec.Stage = "prod"
+
+	result, err := Diff(context.Background(), wf, desired)
+	require.NoError(t, err)
+	assert.Equal(t, "dryRun=All", dryRunQuery)
+	assert.True(t, result.Exists)
+	assert.Equal(t, []FieldDiff{
+		{Path: "spec.stage", Operation: DiffChanged, Live: "nonprod", Desired: "prod"},
+	}, result.Changes)
+	assert.Len(t, result.Warnings, 1)
+	assert.Empty(t, desired.ResourceVersion)
+}
@@ -47,3 +47,27 @@ func (e *ErrProfileInvalid) Error() string {
 func NewProfileInvalidError(message, profile string) error {
 	return &ErrProfileInvalid{message: message, profile: profile}
 }
+
+// ErrServerKeyChanged indicates the server presented a different public key to the one pinned when
+// it was first trusted
+type ErrServerKeyChanged struct {
+	// Server is the name of the server connected to
+	Server string
+	// Pinned is the pin of the trusted public key
+	Pinned string
+	// Presented is the pin of the public key the server presented
+	Presented string
+}
+
+func (e *ErrServerKeyChanged) Error() string {
+	return fmt.Sprintf("the public key of server %s has changed since it was first trusted (pinned %s, presented %s): "+
+		"the server may have been replaced or the connection intercepted - if the change is expected, trust the server again",
+		e.Server, e.Pinned, e.Presented)
+}
+
+// IsServerKeyChanged checks if the error is due to the server presenting a different public key
+// to the one pinned
+func IsServerKeyChanged(err error) bool {
+	var keyErr *ErrServerKeyChanged
+	return errors.As(err, &keyErr)
+}
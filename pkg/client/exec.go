@@ -0,0 +1,154 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	types "github.com/appvia/wfclient/pkg/apitypes"
+	"github.com/appvia/wfclient/pkg/client/config"
+	"github.com/appvia/wfclient/pkg/common"
+)
+
+const (
+	// EnvExecInfo is the environment variable providing an exec credential command with details
+	// of the request, as JSON
+	EnvExecInfo = "WAYFINDER_EXEC_INFO"
+	// execExpiryMargin is how long before expiry we consider a cached token expired
+	execExpiryMargin = 30 * time.Second
+)
+
+// ExecInfo is passed to exec credential commands in the WAYFINDER_EXEC_INFO environment variable
+type ExecInfo struct {
+	// APIVersion is the version of the exec credential protocol
+	APIVersion string `json:"apiVersion"`
+	// Server is the endpoint of the Wayfinder API the token is for
+	Server string `json:"server,omitempty"`
+}
+
+// execCredential is the output expected from an exec credential command
+type execCredential struct {
+	// APIVersion optionally declares the protocol version the command spoke
+	APIVersion string `json:"apiVersion,omitempty"`
+	types.IssuedToken
+}
+
+// execCache caches the tokens returned by exec credential commands
+var execCache = &execCredentialCache{tokens: map[string]*types.IssuedToken{}}
+
+type execCredentialCache struct {
+	sync.Mutex
+	tokens map[string]*types.IssuedToken
+}
+
+// execCacheKey identifies a command and the server it was run for
+func execCacheKey(e *config.ExecConfig, server string) string {
+	encoded, _ := json.Marshal(struct {
+		Exec   *config.ExecConfig
+		Server string
+	}{e, server})
+
+	return string(encoded)
+}
+
+// GetExecToken returns the token provided by the exec credential command, running the command
+// only if no unexpired token is cached
+func GetExecToken(ctx context.Context, e *config.ExecConfig, server string) (string, error) {
+	key := execCacheKey(e, server)
+
+	execCache.Lock()
+	defer execCache.Unlock()
+
+	if cached, found := execCache.tokens[key]; found {
+		if cached.Expires == 0 || time.Now().Add(execExpiryMargin).Before(time.Unix(cached.Expires, 0)) {
+			return cached.Token, nil
+		}
+	}
+
+	issued, err := runExecCommand(ctx, e, server)
+	if err != nil {
+		return "", err
+	}
+	execCache.tokens[key] = issued
+
+	return issued.Token, nil
+}
+
+// forgetExecToken removes any cached token for the command, such as when the API rejects it
+func forgetExecToken(e *config.ExecConfig, server string) {
+	execCache.Lock()
+	defer execCache.Unlock()
+
+	delete(execCache.tokens, execCacheKey(e, server))
+}
+
+// runExecCommand runs the exec credential command and decodes the token it writes to stdout
+func runExecCommand(ctx context.Context, e *config.ExecConfig, server string) (*types.IssuedToken, error) {
+	if e.Command == "" {
+		return nil, errors.New("exec credential command is not set")
+	}
+	if e.APIVersion != "" && e.APIVersion != config.ExecAPIVersion {
+		return nil, fmt.Errorf("exec credential apiVersion %q is not supported, expected %q", e.APIVersion, config.ExecAPIVersion)
+	}
+
+	info, err := json.Marshal(ExecInfo{APIVersion: config.ExecAPIVersion, Server: server})
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, e.Command, e.Args...)
+	cmd.Env = append(os.Environ(), EnvExecInfo+"="+string(info))
+	for _, env := range e.Env {
+		cmd.Env = append(cmd.Env, env.Name+"="+env.Value)
+	}
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	common.Log(ctx).WithField("command", e.Command).Debug("running exec credential command")
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg != "" {
+			return nil, fmt.Errorf("exec credential command %s failed: %w: %s", e.Command, err, msg)
+		}
+		return nil, fmt.Errorf("exec credential command %s failed: %w", e.Command, err)
+	}
+
+	cred := &execCredential{}
+	if err := json.Unmarshal(stdout.Bytes(), cred); err != nil {
+		return nil, fmt.Errorf("exec credential command %s returned invalid output: %w", e.Command, err)
+	}
+	if cred.APIVersion != "" && cred.APIVersion != config.ExecAPIVersion {
+		return nil, fmt.Errorf("exec credential command %s returned apiVersion %q, expected %q", e.Command, cred.APIVersion, config.ExecAPIVersion)
+	}
+	if cred.Token == "" {
+		return nil, fmt.Errorf("exec credential command %s did not return a token", e.Command)
+	}
+
+	return &cred.IssuedToken, nil
+}
@@ -0,0 +1,113 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/appvia/wfclient/pkg/client/config"
+)
+
+// writeExecScript writes a credential script which records each invocation and prints output
+func writeExecScript(t *testing.T, output string) (string, string) {
+	if runtime.GOOS == "windows" {
+		t.Skip("exec credential tests require a POSIX shell")
+	}
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	script := filepath.Join(dir, "credential.sh")
+	content := fmt.Sprintf("#!/bin/sh\necho \"$WAYFINDER_EXEC_INFO $TEAM\" >> %s\ncat <<'EOF'\n%s\nEOF\n", calls, output)
+	require.NoError(t, os.WriteFile(script, []byte(content), 0700))
+
+	return script, calls
+}
+
+func execCalls(t *testing.T, path string) []string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestExecCredentialAuthorization(t *testing.T) {
+	expires := time.Now().Add(time.Hour).Unix()
+	script, calls := writeExecScript(t, fmt.Sprintf(`{"token":"exec-token","expires":%d}`, expires))
+
+	cfg := config.NewEmpty()
+	cfg.CreateProfile("test", "http://wayfinder.test")
+	cfg.AddAuthInfo("test", &config.AuthInfo{Exec: &config.ExecConfig{
+		APIVersion: config.ExecAPIVersion,
+		Command:    script,
+		Env:        []config.ExecEnvVar{{Name: "TEAM", Value: "platform"}},
+	}})
+	cfg.CurrentProfile = "test"
+
+	var headers []string
+	c, err := New(cfg, UseRequestDo(func(req *http.Request) (*http.Response, error) {
+		headers = append(headers, req.Header.Get("Authorization"))
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("{}")), Request: req}, nil
+	}))
+	require.NoError(t, err)
+
+	require.NoError(t, c.Request().Endpoint("/one").Get().Error())
+	require.NoError(t, c.Request().Endpoint("/two").Get().Error())
+
+	assert.Equal(t, []string{"Bearer exec-token", "Bearer exec-token"}, headers)
+	invocations := execCalls(t, calls)
+	require.Len(t, invocations, 1, "token should be cached until it expires")
+	assert.Equal(t, `{"apiVersion":"wayfinder.appvia.io/v1","server":"http://wayfinder.test"} platform`, invocations[0])
+	assert.Equal(t, "exec", cfg.GetProfileAuthMethod("test"))
+}
+
+func TestExecCredentialExpired(t *testing.T) {
+	expired := time.Now().Add(-time.Minute).Unix()
+	script, calls := writeExecScript(t, fmt.Sprintf(`{"token":"old","expires":%d}`, expired))
+	e := &config.ExecConfig{Command: script}
+
+	for i := 0; i < 2; i++ {
+		token, err := GetExecToken(context.Background(), e, "http://expired.test")
+		require.NoError(t, err)
+		assert.Equal(t, "old", token)
+	}
+	assert.Len(t, execCalls(t, calls), 2)
+}
+
+func TestExecCredentialErrors(t *testing.T) {
+	script, _ := writeExecScript(t, `{"expires":0}`)
+	_, err := GetExecToken(context.Background(), &config.ExecConfig{Command: script}, "http://errors.test")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "did not return a token")
+
+	_, err = GetExecToken(context.Background(), &config.ExecConfig{Command: script, APIVersion: "v0"}, "http://errors.test")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not supported")
+
+	_, err = GetExecToken(context.Background(), &config.ExecConfig{Command: filepath.Join(t.TempDir(), "missing")}, "http://errors.test")
+	require.Error(t, err)
+}
@@ -0,0 +1,539 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/appvia/wfclient/pkg/common"
+	"github.com/appvia/wfclient/pkg/utils/httputils"
+	"github.com/appvia/wfclient/pkg/utils/validation"
+	"github.com/appvia/wfclient/pkg/version"
+)
+
+// EnvHARFile is the environment variable naming a file to record an HTTP archive of requests to
+const EnvHARFile = "WAYFINDER_HAR_FILE"
+
+// HAR is an HTTP archive, as described by http://www.softwareishard.com/blog/har-12-spec/
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+// HARLog is the log of an HTTP archive
+type HARLog struct {
+	Version string      `json:"version"`
+	Creator HARCreator  `json:"creator"`
+	Entries []*HAREntry `json:"entries"`
+}
+
+// HARCreator identifies the application which created the archive
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry is a single request made by the client. Retried requests are recorded as separate entries.
+type HAREntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	// Warnings are the validation warnings returned by the server
+	Warnings []string `json:"_warnings,omitempty"`
+	// Error is the error if the request failed without a response
+	Error string `json:"_error,omitempty"`
+}
+
+// HARRequest is the request of an entry
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+// HARResponse is the response of an entry
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+// HARNameValue is a header, cookie or query parameter
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARPostData is the body of a request
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// HARContent is the body of a response
+type HARContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+// HARTimings are the phases of a request in milliseconds, with -1 for phases which did not apply
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// harTrailer ends the archive after the entries
+const harTrailer = "\n  ]\n}}\n"
+
+// HARRecorder records the requests made by the client to an HTTP archive. Credentials and secrets
+// are redacted from the headers and bodies recorded. Entries are streamed to the writer as each
+// request completes. Where the writer can seek, such as a file, the archive is finished after every
+// entry, otherwise it is finished when the recorder is closed.
+type HARRecorder struct {
+	// mu guards the recorder and the writes to the archive
+	mu sync.Mutex
+	// w is where the archive is written
+	w io.Writer
+	// seeker is set when the writer can seek, so the trailer is rewritten after each entry
+	seeker io.Seeker
+	// closer is closed with the recorder, where the recorder owns the writer
+	closer io.Closer
+	// creator identifies the client in the archive
+	creator HARCreator
+	// started indicates the start of the archive has been written
+	started bool
+	// written is the number of entries written
+	written int
+	// refs is the number of clients using the recorder
+	refs int
+	// closed indicates the archive has been finished
+	closed bool
+}
+
+// NewHARRecorder returns a recorder writing an archive to the writer. A writer which can seek holds
+// a complete archive after every request, otherwise the archive is only complete once Close is
+// called. The writer should not be shared with another recorder.
+func NewHARRecorder(w io.Writer) *HARRecorder {
+	h := &HARRecorder{
+		w:       w,
+		creator: HARCreator{Name: "wfclient", Version: version.Release},
+		refs:    1,
+	}
+	// pipes and terminals satisfy io.Seeker but fail to seek
+	if s, ok := w.(io.Seeker); ok {
+		if _, err := s.Seek(0, io.SeekCurrent); err == nil {
+			h.seeker = s
+		}
+	}
+
+	return h
+}
+
+// harFiles holds the recorders for files named by EnvHARFile, so clients in the same process
+// record to the same archive
+var harFiles = struct {
+	sync.Mutex
+	recorders map[string]*HARRecorder
+}{recorders: map[string]*HARRecorder{}}
+
+// harRecorderFromEnv returns the recorder for the file named by EnvHARFile, if set. The file holds a
+// complete archive from the start and after every request, so clients need not be closed. Once the
+// last client using it is closed, a new client starts a new archive.
+func harRecorderFromEnv() (*HARRecorder, error) {
+	path := os.Getenv(EnvHARFile)
+	if path == "" {
+		return nil, nil
+	}
+
+	harFiles.Lock()
+	defer harFiles.Unlock()
+
+	if recorder, found := harFiles.recorders[path]; found {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		if !recorder.closed {
+			recorder.refs++
+			return recorder, nil
+		}
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+	recorder := NewHARRecorder(file)
+	recorder.closer = file
+	if err := recorder.writeHeader(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := recorder.checkpoint(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	harFiles.recorders[path] = recorder
+
+	return recorder, nil
+}
+
+// Close finishes the archive once every client using the recorder has closed it, closing the
+// writer if the recorder opened it
+func (h *HARRecorder) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil
+	}
+	if h.refs--; h.refs > 0 {
+		return nil
+	}
+	h.closed = true
+
+	if !h.started {
+		if err := h.writeHeader(); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(h.w, harTrailer); err != nil {
+		return err
+	}
+	if h.closer != nil {
+		return h.closer.Close()
+	}
+
+	return nil
+}
+
+// record performs the request and records it. Responses which are streamed are recorded as they are
+// read, otherwise the body is read up front so the entry is complete when the request returns.
+func (h *HARRecorder) record(req *http.Request, stream bool, do RequestDo) (*http.Response, error) {
+	entry := &HAREntry{StartedDateTime: time.Now()}
+
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	entry.Request = newHARRequest(req, body)
+
+	timer := &harTimer{start: entry.StartedDateTime}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), timer.trace()))
+
+	resp, err := do(req)
+	timer.mark(&timer.responded)
+	if err != nil {
+		entry.Error = err.Error()
+		entry.Response = HARResponse{Cookies: []HARNameValue{}, Headers: []HARNameValue{}, HeadersSize: -1, BodySize: -1}
+		h.complete(entry, timer, nil)
+
+		return nil, err
+	}
+
+	entry.Response = newHARResponse(resp)
+	entry.Warnings = resp.Header.Values(validation.WarningHeader)
+
+	if stream {
+		resp.Body = httputils.CaptureBody(resp.Body, func(body []byte) {
+			h.complete(entry, timer, body)
+		})
+
+		return resp, nil
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(content))
+	h.complete(entry, timer, content)
+
+	return resp, err
+}
+
+// complete fills in the response body and timings of the entry and writes the archive
+func (h *HARRecorder) complete(entry *HAREntry, timer *harTimer, body []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if entry.Error == "" {
+		entry.Response.BodySize = int64(len(body))
+		entry.Response.Content.Size = int64(len(body))
+		entry.Response.Content.Text = common.RedactBody(body)
+	}
+	entry.Timings = timer.timings(time.Now())
+	for _, phase := range []float64{entry.Timings.Blocked, entry.Timings.DNS, entry.Timings.Connect,
+		entry.Timings.Send, entry.Timings.Wait, entry.Timings.Receive} {
+		if phase > 0 {
+			entry.Time += phase
+		}
+	}
+
+	if err := h.writeEntry(entry); err != nil {
+		common.LogWithoutContext().WithError(err).Debug("failed to write HTTP archive entry")
+	}
+}
+
+// writeEntry streams the entry into the archive, starting the archive with the first entry
+func (h *HARRecorder) writeEntry(entry *HAREntry) error {
+	if h.closed {
+		return errors.New("HTTP archive is closed")
+	}
+
+	encoded, err := json.MarshalIndent(entry, "    ", "  ")
+	if err != nil {
+		return err
+	}
+
+	if !h.started {
+		if err := h.writeHeader(); err != nil {
+			return err
+		}
+	}
+	separator := ",\n    "
+	if h.written == 0 {
+		separator = "\n    "
+	}
+	if _, err := io.WriteString(h.w, separator); err != nil {
+		return err
+	}
+	if _, err := h.w.Write(encoded); err != nil {
+		return err
+	}
+	h.written++
+
+	return h.checkpoint()
+}
+
+// writeHeader writes the start of the archive, up to the entries
+func (h *HARRecorder) writeHeader() error {
+	creator, err := json.Marshal(h.creator)
+	if err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintf(h.w, "{\"log\": {\n  \"version\": \"1.2\",\n  \"creator\": %s,\n  \"entries\": [", creator); err != nil {
+		return err
+	}
+	h.started = true
+
+	return nil
+}
+
+// checkpoint finishes the archive written so far when the writer can seek, then seeks back so the
+// trailer is overwritten by the next entry
+func (h *HARRecorder) checkpoint() error {
+	if h.seeker == nil {
+		return nil
+	}
+	if _, err := io.WriteString(h.w, harTrailer); err != nil {
+		return err
+	}
+	_, err := h.seeker.Seek(-int64(len(harTrailer)), io.SeekCurrent)
+
+	return err
+}
+
+func newHARRequest(req *http.Request, body []byte) HARRequest {
+	request := HARRequest{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []HARNameValue{},
+		Headers:     harHeaders(req.Header),
+		QueryString: []HARNameValue{},
+		HeadersSize: -1,
+		BodySize:    int64(len(body)),
+	}
+	for name, values := range req.URL.Query() {
+		for _, value := range values {
+			request.QueryString = append(request.QueryString, HARNameValue{Name: name, Value: value})
+		}
+	}
+	sort.SliceStable(request.QueryString, func(i, j int) bool {
+		return request.QueryString[i].Name < request.QueryString[j].Name
+	})
+	if len(body) > 0 {
+		request.PostData = &HARPostData{
+			MimeType: req.Header.Get("Content-Type"),
+			Text:     common.RedactBody(body),
+		}
+	}
+
+	return request
+}
+
+func newHARResponse(resp *http.Response) HARResponse {
+	proto := resp.Proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+
+	return HARResponse{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: proto,
+		Cookies:     []HARNameValue{},
+		Headers:     harHeaders(resp.Header),
+		Content:     HARContent{MimeType: resp.Header.Get("Content-Type")},
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+	}
+}
+
+// harHeaders returns the headers in name order, with credentials redacted
+func harHeaders(headers http.Header) []HARNameValue {
+	list := []HARNameValue{}
+	for name, values := range common.RedactHeaders(headers) {
+		for _, value := range values {
+			list = append(list, HARNameValue{Name: name, Value: value})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list
+}
+
+// harTimer captures the phases of a request with httptrace
+type harTimer struct {
+	sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	gotConn      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	responded    time.Time
+}
+
+func (t *harTimer) mark(at *time.Time) {
+	t.Lock()
+	defer t.Unlock()
+
+	*at = time.Now()
+}
+
+func (t *harTimer) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { t.mark(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { t.mark(&t.dnsDone) },
+		ConnectStart:         func(_, _ string) { t.mark(&t.connectStart) },
+		ConnectDone:          func(_, _ string, _ error) { t.mark(&t.connectDone) },
+		TLSHandshakeStart:    func() { t.mark(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.mark(&t.tlsDone) },
+		GotConn:              func(httptrace.GotConnInfo) { t.mark(&t.gotConn) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.mark(&t.wroteRequest) },
+		GotFirstResponseByte: func() { t.mark(&t.firstByte) },
+	}
+}
+
+// timings returns the phases of the request. Where the request was not traced, for instance when
+// a custom request handler is used, the time to the response is recorded as waiting.
+func (t *harTimer) timings(done time.Time) HARTimings {
+	t.Lock()
+	defer t.Unlock()
+
+	timings := HARTimings{
+		Blocked: -1,
+		DNS:     harDuration(t.dnsStart, t.dnsDone),
+		Connect: -1,
+		SSL:     harDuration(t.tlsStart, t.tlsDone),
+	}
+	// the connect phase includes the TLS handshake
+	if !t.connectStart.IsZero() {
+		connected := t.connectDone
+		if t.tlsDone.After(connected) {
+			connected = t.tlsDone
+		}
+		timings.Connect = harDuration(t.connectStart, connected)
+	}
+
+	sent, firstByte := t.start, t.responded
+	if !t.gotConn.IsZero() {
+		blocked := harDuration(t.start, t.gotConn)
+		for _, phase := range []float64{timings.DNS, timings.Connect} {
+			if phase > 0 {
+				blocked -= phase
+			}
+		}
+		if blocked < 0 {
+			blocked = 0
+		}
+		timings.Blocked = blocked
+		sent = t.gotConn
+	}
+	if !t.wroteRequest.IsZero() {
+		timings.Send = harDuration(sent, t.wroteRequest)
+		sent = t.wroteRequest
+	}
+	if !t.firstByte.IsZero() {
+		firstByte = t.firstByte
+	}
+	timings.Wait = harDuration(sent, firstByte)
+	timings.Receive = harDuration(firstByte, done)
+
+	return timings
+}
+
+// harDuration returns the milliseconds between the times, or -1 if either is unset
+func harDuration(from, to time.Time) float64 {
+	if from.IsZero() || to.IsZero() {
+		return -1
+	}
+	if to.Before(from) {
+		return 0
+	}
+
+	return float64(to.Sub(from)) / float64(time.Millisecond)
+}
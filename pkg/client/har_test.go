@@ -0,0 +1,169 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/appvia/wfclient/pkg/client/config"
+	"github.com/appvia/wfclient/pkg/utils/validation"
+)
+
+func newTestHARClient(t *testing.T, endpoint string, options ...OptionFunc) SessionClient {
+	cfg := config.NewEmpty()
+	cfg.CreateProfile("test", endpoint)
+	cfg.CurrentProfile = "test"
+	token := "api-secret"
+	cfg.AddAuthInfo("test", &config.AuthInfo{Token: &token})
+
+	c, err := NewSessionClient(cfg, options...)
+	require.NoError(t, err)
+
+	return c
+}
+
+func TestHARRecorder(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/things/one":
+			if atomic.AddInt32(&requests, 1) == 1 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.Header().Set(validation.WarningHeader, `{"message":"deprecated"}`)
+			_, _ = w.Write([]byte(`{"name":"one","token":"thing-secret"}`))
+		case "/api/v2/things/one/logs":
+			_, _ = w.Write([]byte("line one\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	// the writer can neither seek nor be reset, so the archive must be written as one document
+	archive := &bytes.Buffer{}
+	c := newTestHARClient(t, server.URL, UseHARRecorder(struct{ io.Writer }{archive}))
+
+	result := map[string]interface{}{}
+	require.NoError(t, c.Request().Context(context.Background()).Endpoint("/things/one").Result(&result).Get().Error())
+	assert.Equal(t, "thing-secret", result["token"])
+
+	stream, err := c.Request().Context(context.Background()).Endpoint("/things/one/logs").Follow(true).Get().Do()
+	require.NoError(t, err)
+	logs, err := io.ReadAll(stream.Body())
+	require.NoError(t, err)
+	assert.Equal(t, "line one\n", string(logs))
+
+	require.NoError(t, c.Close())
+
+	assert.NotContains(t, archive.String(), "api-secret")
+	assert.NotContains(t, archive.String(), "thing-secret")
+
+	har := &HAR{}
+	require.NoError(t, json.Unmarshal(archive.Bytes(), har))
+	assert.Equal(t, "1.2", har.Log.Version)
+	require.Len(t, har.Log.Entries, 3)
+
+	retried, fetched, streamed := har.Log.Entries[0], har.Log.Entries[1], har.Log.Entries[2]
+	assert.Equal(t, http.StatusTooManyRequests, retried.Response.Status)
+	assert.Equal(t, http.StatusOK, fetched.Response.Status)
+	assert.Equal(t, http.MethodGet, fetched.Request.Method)
+	assert.Equal(t, server.URL+"/api/v2/things/one", fetched.Request.URL)
+	assert.Contains(t, fetched.Request.Headers, HARNameValue{Name: "Authorization", Value: "[redacted]"})
+	assert.Equal(t, []string{`{"message":"deprecated"}`}, fetched.Warnings)
+	assert.Contains(t, fetched.Response.Content.Text, `"name":"one"`)
+	assert.Equal(t, "line one\n", streamed.Response.Content.Text)
+
+	for _, entry := range har.Log.Entries {
+		assert.GreaterOrEqual(t, entry.Timings.Blocked, float64(0))
+		assert.GreaterOrEqual(t, entry.Timings.Send, float64(0))
+		assert.GreaterOrEqual(t, entry.Timings.Wait, float64(0))
+		assert.GreaterOrEqual(t, entry.Timings.Receive, float64(0))
+		assert.Equal(t, float64(-1), entry.Timings.SSL)
+	}
+}
+
+func TestHARRecorderFromEnv(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "client.har")
+	t.Setenv(EnvHARFile, path)
+
+	readArchive := func() *HAR {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		har := &HAR{}
+		require.NoError(t, json.Unmarshal(data, har))
+		return har
+	}
+
+	// clients in the same process share the archive, which is complete after every request
+	first, second := newTestHARClient(t, server.URL), newTestHARClient(t, server.URL)
+	assert.Empty(t, readArchive().Log.Entries)
+	for i, c := range []Interface{first, second} {
+		require.NoError(t, c.Request().Context(context.Background()).Endpoint("/serverinfo").Get().Error())
+		assert.Len(t, readArchive().Log.Entries, i+1)
+	}
+
+	require.NoError(t, first.Close())
+	require.NoError(t, second.Close())
+	assert.Len(t, readArchive().Log.Entries, 2)
+}
+
+func TestHARRecorderRequestError(t *testing.T) {
+	archive := &bytes.Buffer{}
+	c := newTestHARClient(t, "http://wayfinder.test", UseHARRecorder(archive),
+		UseRequestDo(func(req *http.Request) (*http.Response, error) {
+			return nil, assert.AnError
+		}))
+
+	require.Error(t, c.Request().Context(context.Background()).Endpoint("/serverinfo").Get().Error())
+	require.NoError(t, c.Close())
+
+	har := &HAR{}
+	require.NoError(t, json.Unmarshal(archive.Bytes(), har))
+	require.Len(t, har.Log.Entries, 1)
+	assert.Equal(t, assert.AnError.Error(), har.Log.Entries[0].Error)
+}
+
+func TestHARRecorderEmpty(t *testing.T) {
+	archive := &bytes.Buffer{}
+	recorder := NewHARRecorder(archive)
+	require.NoError(t, recorder.Close())
+	require.NoError(t, recorder.Close())
+
+	har := &HAR{}
+	require.NoError(t, json.Unmarshal(archive.Bytes(), har))
+	assert.Equal(t, "wfclient", har.Log.Creator.Name)
+	assert.Empty(t, har.Log.Entries)
+}
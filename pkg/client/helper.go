@@ -17,15 +17,23 @@
 package client
 
 import (
+	"errors"
 	"net/http"
-	"strings"
 
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	corev1 "github.com/appvia/wfclient/pkg/apis/core/v1alpha1"
+	"github.com/appvia/wfclient/pkg/utils/validation"
 )
 
+// ReasonDependencyViolation indicates a delete was refused as other objects depend on the object
+const ReasonDependencyViolation = metav1.StatusReason(validation.CauseTypeDependencyViolation)
+
+// CauseTypeObjectModified indicates a conflict was caused by the object being modified since it
+// was read
+const CauseTypeObjectModified = metav1.CauseType("ObjectModified")
+
 // IsNotFound check if error is an 404 error
 func IsNotFound(err error) bool {
 	return isExpectedError(err, http.StatusNotFound)
@@ -61,9 +69,86 @@ func IsBadRequest(err error) bool {
 	return isExpectedError(err, http.StatusBadRequest)
 }
 
+// IsGone checks if the response was a 410, i.e. the requested resource version or token is no
+// longer available
+func IsGone(err error) bool {
+	return isExpectedError(err, http.StatusGone) || ReasonForError(err) == metav1.StatusReasonGone
+}
+
+// IsTooManyRequests checks if the response was a 429
+func IsTooManyRequests(err error) bool {
+	return isExpectedError(err, http.StatusTooManyRequests)
+}
+
+// IsTimeout checks if the server timed out handling the request
+func IsTimeout(err error) bool {
+	switch ReasonForError(err) {
+	case metav1.StatusReasonTimeout, metav1.StatusReasonServerTimeout:
+		return true
+	}
+	return isExpectedError(err, http.StatusRequestTimeout) || isExpectedError(err, http.StatusGatewayTimeout)
+}
+
+// IsConflict checks if the request conflicted with a concurrent change to the object, i.e. the
+// object has been modified since it was read
+func IsConflict(err error) bool {
+	return ReasonForError(err) == metav1.StatusReasonConflict
+}
+
+// IsDependencyViolation checks if the request was refused because other objects depend on the
+// object
+func IsDependencyViolation(err error) bool {
+	return ReasonForError(err) == ReasonDependencyViolation || validation.IsDependencyViolationError(err)
+}
+
+// IsValidation checks if the request was rejected as the object failed validation
+func IsValidation(err error) bool {
+	if ReasonForError(err) == metav1.StatusReasonInvalid {
+		return true
+	}
+	verr := &validation.Error{}
+	return errors.As(err, &verr)
+}
+
+// IsRetryable checks if the request failed for a transient reason, such that the same request may
+// succeed if retried after a delay
+func IsRetryable(err error) bool {
+	if IsTooManyRequests(err) || IsServiceUnavailable(err) || IsTimeout(err) || IsConflict(err) {
+		return true
+	}
+	return isExpectedError(err, http.StatusBadGateway)
+}
+
+// ReasonForError returns the reason for the error if it is (or wraps) an error returned by the
+// API, or an empty reason if not
+func ReasonForError(err error) metav1.StatusReason {
+	if e, ok := asAPIError(err); ok {
+		return e.Reason
+	}
+
+	return kerrors.ReasonForError(err)
+}
+
+// asAPIError finds the first APIError in the error's chain
+func asAPIError(err error) (*APIError, bool) {
+	if err == nil {
+		return nil, false
+	}
+	var ptr *APIError
+	if errors.As(err, &ptr) && ptr != nil {
+		return ptr, true
+	}
+	var val APIError
+	if errors.As(err, &val) {
+		return &val, true
+	}
+
+	return nil, false
+}
+
 // isExpectError checks if the error an apiError and compares the code
 func isExpectedError(err error, code int) bool {
-	e, ok := (err).(*APIError)
+	e, ok := asAPIError(err)
 	if !ok {
 		return false
 	}
@@ -72,12 +157,69 @@ func isExpectedError(err error, code int) bool {
 }
 
 func IsAlreadyExists(err error) bool {
-	return kerrors.IsAlreadyExists(err) ||
-		strings.Contains(err.Error(), "already exists") // TODO: Fix error types we return
+	return ReasonForError(err) == metav1.StatusReasonAlreadyExists
 }
 
+// IsObjectModified checks if a write conflicted because the object was modified since it was read,
+// i.e. the object can be read again and the change retried
 func IsObjectModified(err error) bool {
-	return err != nil && err.Error() == ObjectModifiedError
+	if !IsConflict(err) {
+		return false
+	}
+	for _, cause := range causesForError(err) {
+		if cause.Type == CauseTypeObjectModified || cause.Field == "metadata.resourceVersion" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// causesForError returns the causes provided by the server for the error, if any
+func causesForError(err error) []metav1.StatusCause {
+	if e, ok := asAPIError(err); ok {
+		return e.Causes
+	}
+	var status kerrors.APIStatus
+	if errors.As(err, &status) && status.Status().Details != nil {
+		return status.Status().Details.Causes
+	}
+
+	return nil
+}
+
+// reasonForStatusCode returns a reason for an error where the server has not provided one
+func reasonForStatusCode(code int) metav1.StatusReason {
+	switch code {
+	case http.StatusBadRequest:
+		return metav1.StatusReasonBadRequest
+	case http.StatusUnauthorized:
+		return metav1.StatusReasonUnauthorized
+	case http.StatusForbidden:
+		return metav1.StatusReasonForbidden
+	case http.StatusNotFound:
+		return metav1.StatusReasonNotFound
+	case http.StatusMethodNotAllowed:
+		return metav1.StatusReasonMethodNotAllowed
+	case http.StatusRequestTimeout:
+		return metav1.StatusReasonTimeout
+	case http.StatusConflict:
+		return metav1.StatusReasonConflict
+	case http.StatusGone:
+		return metav1.StatusReasonGone
+	case http.StatusUnprocessableEntity:
+		return metav1.StatusReasonInvalid
+	case http.StatusTooManyRequests:
+		return metav1.StatusReasonTooManyRequests
+	case http.StatusInternalServerError:
+		return metav1.StatusReasonInternalError
+	case http.StatusServiceUnavailable:
+		return metav1.StatusReasonServiceUnavailable
+	case http.StatusGatewayTimeout:
+		return metav1.StatusReasonTimeout
+	}
+
+	return metav1.StatusReasonUnknown
 }
 
 // For returns a versioned resource source for the provided object
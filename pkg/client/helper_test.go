@@ -0,0 +1,185 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/appvia/wfclient/pkg/client/config"
+	"github.com/appvia/wfclient/pkg/utils/validation"
+)
+
+// useJSONHandler answers every request with the status code and the JSON encoded body from the handler
+func useJSONHandler(handler func(req *http.Request) (int, interface{})) OptionFunc {
+	return UseRequestDo(func(req *http.Request) (*http.Response, error) {
+		code, body := handler(req)
+		encoded, _ := json.Marshal(body)
+		return &http.Response{
+			StatusCode: code,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(string(encoded))),
+			Request:    req,
+		}, nil
+	})
+}
+
+// requestError performs a request against a fake server returning the provided response
+func requestError(t *testing.T, code int, header http.Header, body string) error {
+	cfg := config.NewEmpty()
+	cfg.CreateProfile("test", "http://wayfinder.test")
+	cfg.AddAuthInfo("test", &config.AuthInfo{Token: new(string)})
+	cfg.CurrentProfile = "test"
+
+	c, err := New(cfg, UseRequestDo(func(req *http.Request) (*http.Response, error) {
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			StatusCode: code,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	}))
+	require.NoError(t, err)
+
+	return c.Request().Endpoint("/test").Get().Error()
+}
+
+func TestErrorsThroughWrapping(t *testing.T) {
+	err := fmt.Errorf("failed to get thing: %w", requestError(t, http.StatusNotFound, nil, ""))
+
+	assert.True(t, IsNotFound(err))
+	assert.False(t, IsNotAuthorized(err))
+	assert.Equal(t, metav1.StatusReasonNotFound, ReasonForError(err))
+
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusNotFound, apiErr.Code)
+}
+
+func TestErrorIs(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", requestError(t, http.StatusUnauthorized, nil, `{"message":"token expired"}`))
+	assert.True(t, errors.Is(err, ErrAuthenticationRequired))
+	assert.False(t, errors.Is(err, errors.New("token expired")))
+}
+
+func TestErrorReasonFromStatus(t *testing.T) {
+	status := `{"kind":"Status","apiVersion":"v1","status":"Failure","message":"appenvs \"x\" already exists","reason":"AlreadyExists","code":409}`
+	err := requestError(t, http.StatusConflict, nil, status)
+
+	assert.True(t, IsAlreadyExists(err))
+	assert.False(t, IsConflict(err))
+	assert.False(t, IsRetryable(err))
+	assert.Equal(t, `appenvs "x" already exists`, err.Error())
+}
+
+func TestErrorValidationFromStatus(t *testing.T) {
+	status := `{"kind":"Status","status":"Failure","reason":"Invalid","code":422,"details":{"kind":"AppEnv","group":"app.appvia.io","name":"x","causes":[{"type":"FieldValueRequired","field":"spec.stage","message":"stage is required"}]}}`
+	err := requestError(t, http.StatusUnprocessableEntity, nil, status)
+
+	assert.True(t, IsValidation(err))
+	verr := &validation.Error{}
+	require.True(t, errors.As(err, &verr))
+	assert.True(t, verr.ContainsFieldError("spec.stage"))
+}
+
+func TestErrorValidation(t *testing.T) {
+	err := requestError(t, http.StatusBadRequest, nil, `{"message":"invalid","fieldErrors":[{"field":"spec","errCode":"required","message":"required"}]}`)
+	assert.True(t, IsValidation(err))
+	assert.True(t, IsBadRequest(err))
+	assert.Equal(t, metav1.StatusReasonInvalid, ReasonForError(err))
+}
+
+func TestErrorObjectModified(t *testing.T) {
+	header := http.Header{}
+	header.Set("x-wayfinder-objectmodified", "true")
+	err := requestError(t, http.StatusConflict, header, `{"message":"modified"}`)
+
+	assert.True(t, IsConflict(err))
+	assert.True(t, IsObjectModified(err))
+	assert.True(t, IsRetryable(err))
+	assert.Equal(t, ObjectModifiedError, err.Error())
+}
+
+func TestErrorDependencyViolation(t *testing.T) {
+	err := requestError(t, http.StatusConflict, nil, `{"dependents":[{"kind":"AppEnv","name":"x","workspace":"demo"}]}`)
+
+	assert.True(t, IsDependencyViolation(err))
+	assert.True(t, validation.IsDependencyViolationError(err))
+	assert.False(t, IsConflict(err))
+}
+
+func TestErrorConflictWithoutReason(t *testing.T) {
+	// the message is never used to work out the reason
+	err := requestError(t, http.StatusConflict, nil, `{"message":"workspace demo already exists"}`)
+	assert.False(t, IsAlreadyExists(err))
+	assert.True(t, IsConflict(err))
+	assert.False(t, IsObjectModified(err))
+}
+
+func TestErrorStatusObjectModified(t *testing.T) {
+	err := requestError(t, http.StatusConflict, nil, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Conflict","message":"modified",`+
+		`"details":{"causes":[{"field":"metadata.resourceVersion","message":"stale"}]}}`)
+	assert.True(t, IsObjectModified(err))
+
+	err = requestError(t, http.StatusConflict, nil, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Conflict","message":"in use"}`)
+	assert.True(t, IsConflict(err))
+	assert.False(t, IsObjectModified(err))
+
+	assert.True(t, IsObjectModified(kerrors.NewApplyConflict([]metav1.StatusCause{{Field: "metadata.resourceVersion"}}, "modified")))
+}
+
+func TestErrorRetryable(t *testing.T) {
+	cases := []struct {
+		Code     int
+		Expected bool
+	}{
+		{Code: http.StatusTooManyRequests, Expected: true},
+		{Code: http.StatusServiceUnavailable, Expected: true},
+		{Code: http.StatusGatewayTimeout, Expected: true},
+		{Code: http.StatusBadGateway, Expected: true},
+		{Code: http.StatusGone, Expected: false},
+		{Code: http.StatusNotFound, Expected: false},
+		{Code: http.StatusForbidden, Expected: false},
+	}
+	for _, c := range cases {
+		apiErr := &APIError{Code: c.Code, Reason: reasonForStatusCode(c.Code)}
+		assert.Equal(t, c.Expected, IsRetryable(fmt.Errorf("wrapped: %w", apiErr)), "code %d", c.Code)
+	}
+	assert.True(t, IsGone(&APIError{Code: http.StatusGone}))
+	assert.True(t, IsTimeout(&APIError{Code: http.StatusGatewayTimeout}))
+}
+
+func TestReasonForKubernetesError(t *testing.T) {
+	err := kerrors.NewConflict(schema.GroupResource{Group: "app.appvia.io", Resource: "appenvs"}, "x", errors.New("changed"))
+	assert.True(t, IsConflict(err))
+	assert.Equal(t, metav1.StatusReasonConflict, ReasonForError(err))
+	assert.Equal(t, metav1.StatusReason(""), ReasonForError(errors.New("plain")))
+}
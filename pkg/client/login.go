@@ -0,0 +1,119 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+
+	types "github.com/appvia/wfclient/pkg/apitypes"
+	"github.com/appvia/wfclient/pkg/client/config"
+	"github.com/appvia/wfclient/pkg/common"
+)
+
+// LoginLocal logs into the server of the current profile as a local user, storing the issued
+// tokens in the profile
+func (c *cc) LoginLocal(ctx context.Context, username, password string) error {
+	if username == "" || password == "" {
+		return errors.New("a username and password are required")
+	}
+
+	resp := &types.LoginResponse{}
+	if err := c.Request().
+		Context(ctx).
+		Unauthenticated().
+		Endpoint("/login/local").
+		Payload(&types.LocalUser{Username: username, Password: password}).
+		Result(resp).
+		Post().
+		Error(); err != nil {
+		return err
+	}
+	if resp.IssuedToken == nil || resp.IssuedToken.Token == "" {
+		return errors.New("no token was issued by the server")
+	}
+
+	return c.storeIdentity(&config.Identity{
+		RefreshToken: resp.IssuedToken.RefreshToken,
+		Token:        resp.IssuedToken.Token,
+	})
+}
+
+// LoginWithAccessToken logs into the server of the current profile with an access token, storing
+// it in the profile to be exchanged for API tokens as required
+func (c *cc) LoginWithAccessToken(ctx context.Context, token string) error {
+	issued, err := exchangeAccessToken(ctx, c, []byte(token), exchangeTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	return c.storeIdentity(&config.Identity{RefreshToken: token, Token: string(issued)})
+}
+
+// Logout revokes the refresh token of the current profile and clears the stored credentials. An
+// access token is not revoked, as it may be in use elsewhere. The credentials are cleared even if
+// the server cannot be reached.
+func (c *cc) Logout(ctx context.Context) error {
+	auth := c.profileAuthInfo()
+	if auth == nil {
+		return NewProfileInvalidError("missing authentication profile", c.CurrentProfile())
+	}
+
+	if auth.Identity != nil && auth.Identity.RefreshToken != "" && !auth.Identity.IsExchangeToken() {
+		err := c.Request().
+			Context(ctx).
+			Authorization(auth.Identity.RefreshToken).
+			Endpoint("/login/token").
+			Payload(&types.IssuedToken{RefreshToken: auth.Identity.RefreshToken}).
+			Delete().
+			Error()
+		if err != nil && !IsNotFound(err) && !IsNotAuthorized(err) {
+			common.Log(ctx).WithError(err).Warn("failed to revoke refresh token")
+		}
+	}
+
+	auth.Identity = nil
+	auth.Token = nil
+
+	return c.handleConfigurationUpdate()
+}
+
+// storeIdentity replaces the credentials of the current profile with the identity and persists it
+func (c *cc) storeIdentity(identity *config.Identity) error {
+	name := c.CurrentProfile()
+	profile := c.cfg.Profiles[name]
+	if profile == nil {
+		return ErrMissingProfile
+	}
+	if profile.AuthInfo == "" {
+		profile.AuthInfo = name
+	}
+
+	c.cfg.AddAuthInfo(profile.AuthInfo, &config.AuthInfo{Identity: identity})
+
+	return c.handleConfigurationUpdate()
+}
+
+// profileAuthInfo returns the credentials of the current profile, or nil if there are none
+func (c *cc) profileAuthInfo() *config.AuthInfo {
+	profile := c.cfg.Profiles[c.CurrentProfile()]
+	if profile == nil {
+		return nil
+	}
+
+	return c.cfg.AuthInfos[profile.AuthInfo]
+}
@@ -0,0 +1,159 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/appvia/wfclient/pkg/apitypes"
+	"github.com/appvia/wfclient/pkg/authtypes"
+	"github.com/appvia/wfclient/pkg/client/config"
+	"github.com/appvia/wfclient/pkg/common"
+)
+
+// newTestLoginClient returns a client for a profile without credentials, counting the updates
+func newTestLoginClient(t *testing.T, updates *int, handler func(req *http.Request) (int, interface{})) SessionClient {
+	cfg := config.NewEmpty()
+	cfg.CreateProfile("test", "http://wayfinder.test")
+	cfg.CurrentProfile = "test"
+
+	c, err := NewSessionClient(cfg,
+		UseUpdateHandler(func() error {
+			*updates++
+			return nil
+		}),
+		useJSONHandler(handler))
+	require.NoError(t, err)
+
+	return c
+}
+
+func makeTestScopedJWT(t *testing.T, scopes ...string) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"aud":    authtypes.Audience,
+		"scopes": scopes,
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	return token
+}
+
+func TestLoginLocal(t *testing.T) {
+	updates := 0
+	c := newTestLoginClient(t, &updates, func(req *http.Request) (int, interface{}) {
+		assert.Equal(t, "/api/v2/login/local", req.URL.Path)
+		assert.Empty(t, req.Header.Get("Authorization"))
+
+		user := &types.LocalUser{}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(user))
+		if user.Password != "password" {
+			return http.StatusUnauthorized, nil
+		}
+		return http.StatusOK, &types.LoginResponse{IssuedToken: &types.IssuedToken{RefreshToken: "refresh", Token: "token"}}
+	})
+
+	assert.Error(t, c.LoginLocal(context.Background(), "admin", "wrong"))
+	assert.Equal(t, 0, updates)
+
+	require.NoError(t, c.LoginLocal(context.Background(), "admin", "password"))
+	assert.Equal(t, 1, updates)
+
+	auth := c.Config().GetAuthInfo("test")
+	require.NotNil(t, auth.Identity)
+	assert.Equal(t, "refresh", auth.Identity.RefreshToken)
+	assert.Equal(t, "token", auth.Identity.Token)
+	assert.Equal(t, "idtoken", c.Config().GetProfileAuthMethod("test"))
+}
+
+func TestLoginWithAccessToken(t *testing.T) {
+	exchange := makeTestScopedJWT(t, authtypes.ScopeExchange)
+	updates := 0
+	c := newTestLoginClient(t, &updates, func(req *http.Request) (int, interface{}) {
+		assert.Equal(t, "/api/v2/exchange", req.URL.Path)
+		assert.Equal(t, "Bearer "+exchange, req.Header.Get("Authorization"))
+		return http.StatusOK, &types.IssuedToken{Token: "api-token"}
+	})
+
+	assert.Equal(t, ErrNonExchangeToken, c.LoginWithAccessToken(context.Background(), makeTestScopedJWT(t, authtypes.ScopeUser)))
+
+	require.NoError(t, c.LoginWithAccessToken(context.Background(), exchange))
+	assert.Equal(t, 1, updates)
+
+	auth := c.Config().GetAuthInfo("test")
+	require.NotNil(t, auth.Identity)
+	assert.True(t, auth.Identity.IsExchangeToken())
+	assert.Equal(t, "api-token", auth.Identity.Token)
+}
+
+func TestLogout(t *testing.T) {
+	revoked := ""
+	updates := 0
+	c := newTestLoginClient(t, &updates, func(req *http.Request) (int, interface{}) {
+		if req.Method == http.MethodDelete {
+			revoked = req.Header.Get("Authorization")
+			return http.StatusOK, nil
+		}
+		return http.StatusOK, &types.LoginResponse{IssuedToken: &types.IssuedToken{RefreshToken: "refresh", Token: "token"}}
+	})
+
+	require.NoError(t, c.LoginLocal(context.Background(), "admin", "password"))
+	require.NoError(t, c.Logout(context.Background()))
+	assert.Equal(t, "Bearer refresh", revoked)
+	assert.Equal(t, 2, updates)
+	assert.False(t, c.Config().HasAuth("test"))
+}
+
+func TestLogoutServerUnavailable(t *testing.T) {
+	updates := 0
+	c := newTestLoginClient(t, &updates, func(req *http.Request) (int, interface{}) {
+		return http.StatusServiceUnavailable, nil
+	})
+	c.Config().AddAuthInfo("test", &config.AuthInfo{Identity: &config.Identity{RefreshToken: "refresh", Token: "token"}})
+
+	require.NoError(t, c.Logout(context.Background()))
+	assert.False(t, c.Config().HasAuth("test"))
+}
+
+func TestLoginTraceLoggingRedacted(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.SetOutput(out)
+	logger.SetLevel(logrus.TraceLevel)
+	common.SetLogProvider(func() common.Logger { return logger })
+	defer common.SetLogProvider(func() common.Logger { return logrus.StandardLogger() })
+
+	updates := 0
+	c := newTestLoginClient(t, &updates, func(req *http.Request) (int, interface{}) {
+		return http.StatusOK, &types.LoginResponse{IssuedToken: &types.IssuedToken{RefreshToken: "refresh-secret", Token: "token-secret"}}
+	})
+	require.NoError(t, c.LoginLocal(context.Background(), "admin", "password-secret"))
+
+	assert.Contains(t, out.String(), "using the attached payload")
+	assert.Contains(t, out.String(), "we received the following response")
+	assert.NotContains(t, out.String(), "password-secret")
+	assert.NotContains(t, out.String(), "refresh-secret")
+	assert.NotContains(t, out.String(), "token-secret")
+}
@@ -17,6 +17,10 @@
 package client
 
 import (
+	"io"
+	"net/http"
+	"time"
+
 	"github.com/appvia/wfclient/pkg/client/config"
 )
 
@@ -49,3 +53,29 @@ func UseRequestDo(requestDo RequestDo) OptionFunc {
 		c.requestDo = requestDo
 	}
 }
+
+// UseTransportWrapper wraps the transport used for requests, for example to record or trace them,
+// while retaining the TLS and proxy settings of the profile
+func UseTransportWrapper(wrapper func(http.RoundTripper) http.RoundTripper) OptionFunc {
+	return func(c *cc) {
+		c.transportWrapper = wrapper
+	}
+}
+
+// UseHARRecorder records an HTTP archive of every request the client makes to the writer, for
+// debugging. Credentials and secrets are redacted from the archive, which is finished when the
+// client is closed.
+func UseHARRecorder(w io.Writer) OptionFunc {
+	return func(c *cc) {
+		c.har = NewHARRecorder(w)
+	}
+}
+
+// UseTokenExpiryWarning calls the handler when the client is created for each stored access or
+// refresh token which expires within the duration, so users can be warned to rotate them
+func UseTokenExpiryWarning(within time.Duration, handler TokenExpiryHandler) OptionFunc {
+	return func(c *cc) {
+		c.expiryWarning = within
+		c.expiryHandler = handler
+	}
+}
@@ -17,6 +17,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -24,6 +25,9 @@ import (
 	"github.com/appvia/wfclient/pkg/authtypes"
 )
 
+// exchangeTokenTTL is the lifetime of the API tokens requested in exchange for an access token
+const exchangeTokenTTL = 30 * time.Minute
+
 // RefreshWayfinderIdentityToken is used to exchange the refresh token for a new access token
 func RefreshWayfinderIdentityToken(client Interface, refresh []byte) ([]byte, error) {
 	issued := &types.IssuedToken{}
@@ -45,6 +49,11 @@ func RefreshWayfinderIdentityToken(client Interface, refresh []byte) ([]byte, er
 
 // ExchangeAccessToken is used to exchange an access token for a valid API token
 func ExchangeAccessToken(client Interface, exchange []byte, expiration time.Duration) ([]byte, error) {
+	return exchangeAccessToken(context.Background(), client, exchange, expiration)
+}
+
+// exchangeAccessToken exchanges an access token for an API token within the given context
+func exchangeAccessToken(ctx context.Context, client Interface, exchange []byte, expiration time.Duration) ([]byte, error) {
 	if found, err := authtypes.IsExchangeToken(exchange); err != nil {
 		return nil, err
 	} else if !found {
@@ -53,6 +62,7 @@ func ExchangeAccessToken(client Interface, exchange []byte, expiration time.Dura
 
 	token := &types.IssuedToken{}
 	if err := client.Request().
+		Context(ctx).
 		Authorization(string(exchange)).
 		Result(token).
 		Endpoint("/exchange").
@@ -0,0 +1,72 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	appv2beta1 "github.com/appvia/wfclient/pkg/apis/app/v2beta1"
+)
+
+// Scheme holds the Wayfinder object types known to the client. It is used where the client only
+// knows the kind of an object, such as the dependents of an object reported by the API. Add any
+// further types your application uses with Scheme.AddKnownTypes or the API package's Install.
+var Scheme = runtime.NewScheme()
+
+func init() {
+	if err := appv2beta1.Install(Scheme); err != nil {
+		panic(err)
+	}
+}
+
+// NewObjectForKind returns a new, empty object of the named kind with its type information
+// populated, using the types registered with Scheme.
+func NewObjectForKind(kind string) (Object, error) {
+	var gvks []schema.GroupVersionKind
+	for gvk := range Scheme.AllKnownTypes() {
+		if gvk.Kind == kind {
+			gvks = append(gvks, gvk)
+		}
+	}
+	if len(gvks) == 0 {
+		return nil, fmt.Errorf("kind %s is not registered with the client scheme", kind)
+	}
+	// prefer a stable choice where a kind is served by multiple versions
+	sort.Slice(gvks, func(i, j int) bool {
+		return gvks[i].String() < gvks[j].String()
+	})
+
+	for _, gvk := range gvks {
+		o, err := Scheme.New(gvk)
+		if err != nil {
+			return nil, err
+		}
+		obj, ok := o.(Object)
+		if !ok {
+			continue
+		}
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+		return obj, nil
+	}
+
+	return nil, fmt.Errorf("kind %s is not a Wayfinder object", kind)
+}
@@ -0,0 +1,136 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	types "github.com/appvia/wfclient/pkg/apitypes"
+	"github.com/appvia/wfclient/pkg/client/config"
+	jwsutils "github.com/appvia/wfclient/pkg/utils/jwt"
+)
+
+// serviceAccountExpiryMargin is how long before expiry a service account token, or the token it
+// was exchanged for, is considered expired and re-read or exchanged again
+const serviceAccountExpiryMargin = time.Minute
+
+// serviceAccountTokens caches the Wayfinder tokens exchanged for service account tokens
+var serviceAccountTokens = &serviceAccountCache{tokens: map[string]serviceAccountExchange{}}
+
+type serviceAccountCache struct {
+	sync.Mutex
+	tokens map[string]serviceAccountExchange
+}
+
+type serviceAccountExchange struct {
+	// source is the service account token which was exchanged
+	source string
+	// issued is the Wayfinder token it was exchanged for
+	issued *types.IssuedToken
+}
+
+// serviceAccountCacheKey identifies a token file and the server its token was exchanged with
+func serviceAccountCacheKey(sa *config.ServiceAccountConfig, server string) string {
+	return sa.GetTokenPath() + "@" + server
+}
+
+// ReadServiceAccountToken returns the projected service account token held in the file. The file
+// is re-read when it changes, or when the token read is close to expiry in case it has been
+// rotated without the file appearing to change.
+func ReadServiceAccountToken(path string) (string, error) {
+	token, err := ReadTokenFile(path)
+	if err != nil {
+		return "", err
+	}
+	if !isNearExpiry(token) {
+		return token, nil
+	}
+
+	forgetTokenFile(path)
+	if token, err = ReadTokenFile(path); err != nil {
+		return "", err
+	}
+	if claims, err := jwsutils.NewClaimsFromRawToken(token); err == nil && claims.HasExpired() {
+		return "", fmt.Errorf("service account token in %s has expired", path)
+	}
+
+	return token, nil
+}
+
+// isNearExpiry checks if the token expires within the margin. Tokens which cannot be parsed are
+// left for the server to judge.
+func isNearExpiry(token string) bool {
+	claims, err := jwsutils.NewClaimsFromRawToken(token)
+	if err != nil {
+		return false
+	}
+	expiry, found := claims.GetExpiry()
+
+	return found && time.Now().Add(serviceAccountExpiryMargin).After(expiry)
+}
+
+// serviceAccountToken returns the token to authenticate with, either the service account token
+// itself or the Wayfinder token it has been exchanged for
+func (a *apiClient) serviceAccountToken(ctx context.Context, sa *config.ServiceAccountConfig) (string, error) {
+	token, err := ReadServiceAccountToken(sa.GetTokenPath())
+	if err != nil {
+		return "", err
+	}
+	if !sa.Exchange {
+		return token, nil
+	}
+
+	key := serviceAccountCacheKey(sa, a.serverEndpoint())
+
+	serviceAccountTokens.Lock()
+	defer serviceAccountTokens.Unlock()
+
+	if cached, found := serviceAccountTokens.tokens[key]; found && cached.source == token {
+		if cached.issued.Expires == 0 || time.Now().Add(serviceAccountExpiryMargin).Before(time.Unix(cached.issued.Expires, 0)) {
+			return cached.issued.Token, nil
+		}
+	}
+
+	issued := &types.IssuedToken{}
+	if err := a.client.Request().
+		Context(ctx).
+		Authorization(token).
+		Endpoint("/login/kubernetes").
+		Result(issued).
+		Post().
+		Error(); err != nil {
+		return "", fmt.Errorf("failed to exchange service account token: %w", err)
+	}
+	if issued.Token == "" {
+		return "", fmt.Errorf("no token was issued in exchange for the service account token")
+	}
+	serviceAccountTokens.tokens[key] = serviceAccountExchange{source: token, issued: issued}
+
+	return issued.Token, nil
+}
+
+// forgetServiceAccountToken removes any exchanged token for the service account, such as when
+// the API rejects it
+func forgetServiceAccountToken(sa *config.ServiceAccountConfig, server string) {
+	serviceAccountTokens.Lock()
+	defer serviceAccountTokens.Unlock()
+
+	delete(serviceAccountTokens.tokens, serviceAccountCacheKey(sa, server))
+}
@@ -0,0 +1,136 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/appvia/wfclient/pkg/apitypes"
+	"github.com/appvia/wfclient/pkg/client/config"
+)
+
+func makeTestServiceAccountJWT(t *testing.T, subject string, expires time.Time) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"aud": "kubernetes",
+		"sub": subject,
+		"exp": expires.Unix(),
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	return token
+}
+
+// writeServiceAccountToken writes the token as the kubelet would, moving the modification time on
+func writeServiceAccountToken(t *testing.T, path, token string, modified time.Time) {
+	require.NoError(t, os.WriteFile(path, []byte(token), 0600))
+	require.NoError(t, os.Chtimes(path, modified, modified))
+}
+
+func newTestServiceAccountClient(t *testing.T, sa *config.ServiceAccountConfig, handler func(req *http.Request) (int, interface{})) Interface {
+	cfg := config.NewEmpty()
+	cfg.CreateProfile("test", "http://wayfinder.test")
+	cfg.AddAuthInfo("test", &config.AuthInfo{ServiceAccount: sa})
+	cfg.CurrentProfile = "test"
+
+	c, err := New(cfg, useJSONHandler(handler))
+	require.NoError(t, err)
+
+	return c
+}
+
+func TestServiceAccountTokenRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wayfinder")
+	first := makeTestServiceAccountJWT(t, "first", time.Now().Add(time.Hour))
+	writeServiceAccountToken(t, path, first, time.Now())
+
+	var headers []string
+	c := newTestServiceAccountClient(t, &config.ServiceAccountConfig{TokenPath: path}, func(req *http.Request) (int, interface{}) {
+		headers = append(headers, req.Header.Get("Authorization"))
+		return http.StatusOK, nil
+	})
+	assert.Equal(t, "serviceAccount", c.Config().GetProfileAuthMethod("test"))
+
+	require.NoError(t, c.Request().Endpoint("/one").Get().Error())
+	second := makeTestServiceAccountJWT(t, "second", time.Now().Add(time.Hour))
+	writeServiceAccountToken(t, path, second, time.Now().Add(time.Minute))
+	require.NoError(t, c.Request().Endpoint("/two").Get().Error())
+
+	assert.Equal(t, []string{"Bearer " + first, "Bearer " + second}, headers)
+}
+
+func TestReadServiceAccountTokenNearExpiry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wayfinder")
+	modified := time.Now()
+	expiring := makeTestServiceAccountJWT(t, "bot", time.Now().Add(10*time.Second))
+	writeServiceAccountToken(t, path, expiring, modified)
+
+	token, err := ReadServiceAccountToken(path)
+	require.NoError(t, err)
+	assert.Equal(t, expiring, token)
+
+	// a rotated token of the same size and modification time is only noticed through the expiry
+	rotated := makeTestServiceAccountJWT(t, "bot", time.Now().Add(time.Hour))
+	require.Len(t, rotated, len(expiring))
+	writeServiceAccountToken(t, path, rotated, modified)
+
+	token, err = ReadServiceAccountToken(path)
+	require.NoError(t, err)
+	assert.Equal(t, rotated, token)
+
+	writeServiceAccountToken(t, path, makeTestServiceAccountJWT(t, "bot", time.Now().Add(-time.Hour)), modified.Add(time.Minute))
+	_, err = ReadServiceAccountToken(path)
+	assert.Error(t, err)
+}
+
+func TestServiceAccountTokenExchange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wayfinder")
+	first := makeTestServiceAccountJWT(t, "first", time.Now().Add(time.Hour))
+	writeServiceAccountToken(t, path, first, time.Now())
+
+	exchanged := map[string]string{}
+	var headers []string
+	c := newTestServiceAccountClient(t, &config.ServiceAccountConfig{TokenPath: path, Exchange: true}, func(req *http.Request) (int, interface{}) {
+		if req.URL.Path == "/api/v2/login/kubernetes" {
+			sa := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+			exchanged[sa] = "wf-" + sa[len(sa)-8:]
+			return http.StatusOK, &types.IssuedToken{Token: exchanged[sa], Expires: time.Now().Add(time.Hour).Unix()}
+		}
+		headers = append(headers, req.Header.Get("Authorization"))
+		return http.StatusOK, nil
+	})
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, c.Request().Endpoint("/test").Get().Error())
+	}
+	assert.Len(t, exchanged, 1)
+	assert.Equal(t, "Bearer "+exchanged[first], headers[2])
+
+	second := makeTestServiceAccountJWT(t, "second", time.Now().Add(time.Hour))
+	writeServiceAccountToken(t, path, second, time.Now().Add(time.Minute))
+	require.NoError(t, c.Request().Endpoint("/test").Get().Error())
+	assert.Len(t, exchanged, 2)
+	assert.Equal(t, "Bearer "+exchanged[second], headers[3])
+}
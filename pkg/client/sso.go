@@ -0,0 +1,224 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	types "github.com/appvia/wfclient/pkg/apitypes"
+	"github.com/appvia/wfclient/pkg/client/config"
+)
+
+const (
+	// ssoCallbackPath is the path of the loopback listener the authorization code is returned to
+	ssoCallbackPath = "/callback"
+	// ssoClientID identifies this client to the server when authorizing
+	ssoClientID = "wfclient"
+	// defaultSSOTimeout is how long to wait for the user to complete the login
+	defaultSSOTimeout = 5 * time.Minute
+)
+
+// ErrSSOLoginTimeout indicates the user did not complete the login in time
+var ErrSSOLoginTimeout = errors.New("timed out waiting for the login to complete")
+
+// SSOLoginOptions control the browser-based login
+type SSOLoginOptions struct {
+	// OpenBrowser is called with the URL the user must visit to login, by default it is printed
+	// to Out
+	OpenBrowser func(authorizeURL string) error
+	// Out is where instructions for the user are written, defaulting to stderr
+	Out io.Writer
+	// ListenAddress is the loopback address to receive the authorization code on, defaulting to a
+	// random port on 127.0.0.1
+	ListenAddress string
+	// Timeout is how long to wait for the user to login, defaulting to five minutes
+	Timeout time.Duration
+}
+
+// ssoCallback is the result received by the loopback listener
+type ssoCallback struct {
+	code string
+	err  error
+}
+
+// LoginSSO logs into the server of the current profile through its single sign-on provider using
+// the authorization code flow with PKCE, storing the issued tokens in the profile. The user is sent
+// to the server to login, which returns an authorization code to a loopback listener to be
+// exchanged for Wayfinder tokens.
+func (c *cc) LoginSSO(ctx context.Context, options SSOLoginOptions) error {
+	if options.Out == nil {
+		options.Out = os.Stderr
+	}
+	if options.ListenAddress == "" {
+		options.ListenAddress = "127.0.0.1:0"
+	}
+	if options.Timeout == 0 {
+		options.Timeout = defaultSSOTimeout
+	}
+
+	server := c.cfg.GetEffectiveServer(c.CurrentProfile())
+	if server == nil || server.Endpoint == "" {
+		return config.ErrNoProfileEndpoint
+	}
+
+	verifier, err := randomString(32)
+	if err != nil {
+		return err
+	}
+	state, err := randomString(16)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", options.ListenAddress)
+	if err != nil {
+		return fmt.Errorf("failed to start login callback listener: %w", err)
+	}
+	redirectURI := fmt.Sprintf("http://%s%s", listener.Addr().String(), ssoCallbackPath)
+
+	results := make(chan ssoCallback, 1)
+	callback := &http.Server{
+		Handler:           ssoCallbackHandler(state, results),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		_ = callback.Serve(listener)
+	}()
+	defer func() {
+		_ = callback.Close()
+	}()
+
+	authorizeURL := makeSSOAuthorizeURL(server, redirectURI, state, pkceChallenge(verifier))
+	if options.OpenBrowser == nil || options.OpenBrowser(authorizeURL) != nil {
+		fmt.Fprintf(options.Out, "Please visit the following URL to login:\n\n  %s\n\n", authorizeURL)
+	}
+
+	var result ssoCallback
+	select {
+	case result = <-results:
+	case <-time.After(options.Timeout):
+		return ErrSSOLoginTimeout
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if result.err != nil {
+		return result.err
+	}
+
+	resp := &types.LoginResponse{}
+	if err := c.Request().
+		Context(ctx).
+		Unauthenticated().
+		Endpoint("/login/sso/token").
+		Payload(&types.AuthorizationCodeExchange{
+			Code:         result.code,
+			CodeVerifier: verifier,
+			RedirectURI:  redirectURI,
+		}).
+		Result(resp).
+		Post().
+		Error(); err != nil {
+		return fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	if resp.IssuedToken == nil || resp.IssuedToken.Token == "" {
+		return errors.New("no token was issued by the server")
+	}
+
+	return c.storeIdentity(&config.Identity{
+		RefreshToken: resp.IssuedToken.RefreshToken,
+		Token:        resp.IssuedToken.Token,
+	})
+}
+
+// ssoCallbackHandler receives the authorization code from the browser, sending the first valid
+// result on the channel
+func ssoCallbackHandler(state string, results chan<- ssoCallback) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(ssoCallbackPath, func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		if query.Get("state") != state {
+			http.Error(w, "Invalid login state, please try again.", http.StatusBadRequest)
+			return
+		}
+
+		var result ssoCallback
+		switch {
+		case query.Get("error") != "":
+			result.err = fmt.Errorf("login failed: %s %s", query.Get("error"), query.Get("error_description"))
+			http.Error(w, "Login failed, please return to the terminal.", http.StatusUnauthorized)
+		case query.Get("code") == "":
+			result.err = errors.New("login failed: no authorization code was returned")
+			http.Error(w, "Login failed, please return to the terminal.", http.StatusBadRequest)
+		default:
+			result.code = query.Get("code")
+			fmt.Fprintln(w, "Login complete, you may close this window.")
+		}
+
+		select {
+		case results <- result:
+		default:
+		}
+	})
+
+	return mux
+}
+
+// makeSSOAuthorizeURL returns the URL of the server to send the user to for login
+func makeSSOAuthorizeURL(server *config.Server, redirectURI, state, challenge string) string {
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", ssoClientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("state", state)
+	query.Set("code_challenge", challenge)
+	query.Set("code_challenge_method", "S256")
+
+	return fmt.Sprintf("%s%s?%s",
+		strings.TrimSuffix(server.Endpoint, "/"),
+		path.Join("/", server.GetAPIInfo().NonResourceAPI, "login/sso/authorize"),
+		query.Encode())
+}
+
+// pkceChallenge returns the S256 code challenge for the verifier
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// randomString returns a URL-safe random string encoding the number of random bytes
+func randomString(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
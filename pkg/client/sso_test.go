@@ -0,0 +1,138 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/appvia/wfclient/pkg/apitypes"
+	"github.com/appvia/wfclient/pkg/client/config"
+)
+
+// newTestIdentityProvider returns a server standing in for the Wayfinder login endpoints, which
+// authorizes every user unless denied
+func newTestIdentityProvider(t *testing.T, denied bool) *httptest.Server {
+	var challenge, redirect string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v2/login/sso/authorize", func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		assert.Equal(t, "code", query.Get("response_type"))
+		assert.Equal(t, "S256", query.Get("code_challenge_method"))
+		challenge, redirect = query.Get("code_challenge"), query.Get("redirect_uri")
+
+		back := url.Values{"state": {query.Get("state")}}
+		if denied {
+			back.Set("error", "access_denied")
+		} else {
+			back.Set("code", "authorization-code")
+		}
+		http.Redirect(w, req, redirect+"?"+back.Encode(), http.StatusFound)
+	})
+	mux.HandleFunc("/api/v2/login/sso/token", func(w http.ResponseWriter, req *http.Request) {
+		exchange := &types.AuthorizationCodeExchange{}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(exchange))
+
+		sum := sha256.Sum256([]byte(exchange.CodeVerifier))
+		if exchange.Code != "authorization-code" || exchange.RedirectURI != redirect ||
+			base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(&types.LoginResponse{
+			IssuedToken: &types.IssuedToken{RefreshToken: "sso-refresh", Token: "sso-token"},
+		})
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func newTestSSOClient(t *testing.T, endpoint string) SessionClient {
+	cfg := config.NewEmpty()
+	cfg.CreateProfile("test", endpoint)
+	cfg.CurrentProfile = "test"
+
+	c, err := NewSessionClient(cfg)
+	require.NoError(t, err)
+
+	return c
+}
+
+// visit follows the authorize URL as a browser would
+func visit(authorizeURL string) error {
+	resp, err := http.Get(authorizeURL)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func TestLoginSSO(t *testing.T) {
+	idp := newTestIdentityProvider(t, false)
+	defer idp.Close()
+
+	c := newTestSSOClient(t, idp.URL)
+	require.NoError(t, c.LoginSSO(context.Background(), SSOLoginOptions{OpenBrowser: visit}))
+
+	auth := c.Config().GetAuthInfo("test")
+	require.NotNil(t, auth.Identity)
+	assert.Equal(t, "sso-refresh", auth.Identity.RefreshToken)
+	assert.Equal(t, "sso-token", auth.Identity.Token)
+}
+
+func TestLoginSSODenied(t *testing.T) {
+	idp := newTestIdentityProvider(t, true)
+	defer idp.Close()
+
+	c := newTestSSOClient(t, idp.URL)
+	err := c.LoginSSO(context.Background(), SSOLoginOptions{OpenBrowser: visit})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access_denied")
+	assert.False(t, c.Config().HasAuth("test"))
+}
+
+func TestLoginSSOTimeout(t *testing.T) {
+	idp := newTestIdentityProvider(t, false)
+	defer idp.Close()
+
+	out := &bytes.Buffer{}
+	c := newTestSSOClient(t, idp.URL)
+	err := c.LoginSSO(context.Background(), SSOLoginOptions{Out: out, Timeout: 50 * time.Millisecond})
+	assert.Equal(t, ErrSSOLoginTimeout, err)
+	assert.Contains(t, out.String(), idp.URL+"/api/v2/login/sso/authorize?")
+}
+
+func TestSSOCallbackRejectsUnknownState(t *testing.T) {
+	results := make(chan ssoCallback, 1)
+	rec := httptest.NewRecorder()
+	ssoCallbackHandler("expected", results).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/callback?state=other&code=x", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Empty(t, results)
+}
@@ -0,0 +1,75 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenFiles caches the tokens read from token files, keyed by path
+var tokenFiles = &tokenFileCache{files: map[string]tokenFile{}}
+
+type tokenFileCache struct {
+	sync.Mutex
+	files map[string]tokenFile
+}
+
+type tokenFile struct {
+	modified time.Time
+	size     int64
+	token    string
+}
+
+// ReadTokenFile returns the token held in the file, re-reading the file only if it has changed
+// since it was last read. This allows the token to be rotated by other tooling.
+func ReadTokenFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	tokenFiles.Lock()
+	defer tokenFiles.Unlock()
+
+	if cached, found := tokenFiles.files[path]; found && cached.modified.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.token, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+	tokenFiles.files[path] = tokenFile{modified: info.ModTime(), size: info.Size(), token: token}
+
+	return token, nil
+}
+
+// forgetTokenFile removes the cached token for the file, so it is re-read on next use
+func forgetTokenFile(path string) {
+	tokenFiles.Lock()
+	defer tokenFiles.Unlock()
+
+	delete(tokenFiles.files, path)
+}
@@ -0,0 +1,72 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/appvia/wfclient/pkg/client/config"
+)
+
+func TestTokenFileOverrideRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("first\n"), 0600))
+
+	var headers []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	cfg := config.NewEmpty()
+	cfg.CreateProfile("test", server.URL)
+	cfg.AddAuthInfo("test", &config.AuthInfo{Token: new(string)})
+	cfg.CurrentProfile = "test"
+	insecure := true
+	cfg.SetOverrides(config.Overrides{TokenFile: path, InsecureSkipTLSVerify: &insecure})
+
+	c, err := New(cfg)
+	require.NoError(t, err)
+	require.NoError(t, c.Request().RawEndpoint("/one").Get().Error())
+
+	// rotate the token, making sure the modification time moves on
+	require.NoError(t, os.WriteFile(path, []byte("second"), 0600))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+	require.NoError(t, c.Request().RawEndpoint("/two").Get().Error())
+
+	assert.Equal(t, []string{"Bearer first", "Bearer second"}, headers)
+}
+
+func TestReadTokenFileErrors(t *testing.T) {
+	_, err := ReadTokenFile(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+
+	empty := filepath.Join(t.TempDir(), "empty")
+	require.NoError(t, os.WriteFile(empty, nil, 0600))
+	_, err = ReadTokenFile(empty)
+	require.Error(t, err)
+}
@@ -0,0 +1,304 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"golang.org/x/net/http/httpproxy"
+
+	"github.com/appvia/wfclient/pkg/client/config"
+	"github.com/appvia/wfclient/pkg/common"
+	"github.com/appvia/wfclient/pkg/utils/httputils"
+)
+
+// transportKey identifies the TLS and network settings a transport was built for
+type transportKey struct {
+	caCertificate     string
+	pinnedPublicKey   string
+	clientCertificate string
+	clientKey         string
+	insecure          bool
+	serverName        string
+	verifyHost        string
+	proxyURL          string
+	noProxy           string
+}
+
+// httpClientCache caches the http clients built for the servers a client talks to, so connections
+// are kept alive and reused across requests. Servers with the same settings share a client.
+type httpClientCache struct {
+	sync.Mutex
+	// wrapper optionally wraps the transports built
+	wrapper func(http.RoundTripper) http.RoundTripper
+	// entries are the clients keyed on the TLS and network settings
+	entries map[transportKey]*cachedHTTPClient
+	// servers are the settings last used for each named server in the configuration, so clients
+	// are dropped once the servers using them change or are removed
+	servers map[string]transportKey
+}
+
+// cachedHTTPClient holds the clients for a set of server settings
+type cachedHTTPClient struct {
+	// transport is the transport built for the settings, or nil when the default is used
+	transport *http.Transport
+	// client is used for requests, with the default timeout
+	client *http.Client
+	// stream is used to follow streams, without a timeout
+	stream *http.Client
+}
+
+func newHTTPClientCache(wrapper func(http.RoundTripper) http.RoundTripper) *httpClientCache {
+	return &httpClientCache{
+		wrapper: wrapper,
+		entries: map[transportKey]*cachedHTTPClient{},
+		servers: map[string]transportKey{},
+	}
+}
+
+// hasCustomTransport checks if the server needs anything other than the default transport
+func hasCustomTransport(server *config.Server) bool {
+	return server.CACertificate != "" || server.PinnedPublicKey != "" || server.InsecureSkipTLSVerify || server.TLSServerName != "" ||
+		server.HasClientCertificate() || server.ProxyURL != "" || server.NoProxy != ""
+}
+
+// clientFor returns the http client for the settings of the named server, building it if required.
+// Streams are followed with a client which has no timeout.
+func (h *httpClientCache) clientFor(name string, server *config.Server, stream bool) (*http.Client, error) {
+	key, err := transportKeyFor(server)
+	if err != nil {
+		return nil, err
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	// @step: drop the client the server used before its settings changed
+	previous, found := h.servers[name]
+	h.servers[name] = key
+	if found && previous != key {
+		h.evict(previous)
+	}
+
+	entry, found := h.entries[key]
+	if !found {
+		var transport http.RoundTripper = httputils.DefaultTransport
+		entry = &cachedHTTPClient{}
+		if hasCustomTransport(server) {
+			if entry.transport, err = buildTransport(server); err != nil {
+				return nil, err
+			}
+			transport = entry.transport
+		}
+		if h.wrapper != nil {
+			transport = h.wrapper(transport)
+		}
+		entry.client = httputils.NewDefaultHTTPClient(transport)
+		entry.stream = &http.Client{Transport: transport}
+		h.entries[key] = entry
+	}
+
+	if stream {
+		return entry.stream, nil
+	}
+
+	return entry.client, nil
+}
+
+// retain drops the clients of any servers no longer in the configuration
+func (h *httpClientCache) retain(servers map[string]*config.Server) {
+	h.Lock()
+	defer h.Unlock()
+
+	for name, key := range h.servers {
+		if _, found := servers[name]; !found {
+			delete(h.servers, name)
+			h.evict(key)
+		}
+	}
+}
+
+// close drops all the clients, closing their idle connections
+func (h *httpClientCache) close() {
+	h.Lock()
+	defer h.Unlock()
+
+	h.servers = map[string]transportKey{}
+	for key := range h.entries {
+		h.evict(key)
+	}
+}
+
+// evict removes the client for the settings unless a server still uses them
+func (h *httpClientCache) evict(key transportKey) {
+	for _, used := range h.servers {
+		if used == key {
+			return
+		}
+	}
+	if entry, found := h.entries[key]; found {
+		if entry.transport != nil {
+			entry.transport.CloseIdleConnections()
+		}
+		delete(h.entries, key)
+	}
+}
+
+// transportKeyFor returns the key identifying the server's TLS and network settings
+func transportKeyFor(server *config.Server) (transportKey, error) {
+	key := transportKey{
+		caCertificate:   server.CACertificate,
+		pinnedPublicKey: server.PinnedPublicKey,
+		insecure:        server.InsecureSkipTLSVerify,
+		serverName:      server.TLSServerName,
+		proxyURL:        server.ProxyURL,
+		noProxy:         server.NoProxy,
+	}
+	// a pinned transport verifies the certificate against the host it was built for
+	if server.PinnedPublicKey != "" {
+		host, err := verificationHost(server)
+		if err != nil {
+			return key, err
+		}
+		key.verifyHost = host
+	}
+	// key on the content of the client certificate, so rotated certificates are picked up
+	if server.HasClientCertificate() {
+		cert, err := config.LoadPEM(server.ClientCertificate)
+		if err != nil {
+			return key, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		clientKey, err := config.LoadPEM(server.ClientKey)
+		if err != nil {
+			return key, fmt.Errorf("failed to load client key: %w", err)
+		}
+		key.clientCertificate, key.clientKey = string(cert), string(clientKey)
+	}
+
+	return key, nil
+}
+
+// verificationHost returns the host the server certificate must be valid for: the TLS server name
+// if set, otherwise the host of the endpoint, which may be an IP address
+func verificationHost(server *config.Server) (string, error) {
+	if server.TLSServerName != "" {
+		return server.TLSServerName, nil
+	}
+	u, err := url.Parse(server.Endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid server endpoint %q: %w", server.Endpoint, err)
+	}
+
+	return u.Hostname(), nil
+}
+
+// buildTransport creates a transport implementing the server's TLS and proxy settings
+func buildTransport(server *config.Server) (*http.Transport, error) {
+	t := httputils.DefaultTransport.Clone()
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+
+	if server.CACertificate != "" {
+		rootCAs, _ := x509.SystemCertPool()
+		if rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if ok := rootCAs.AppendCertsFromPEM([]byte(server.CACertificate)); !ok {
+			common.LogWithoutContext().Debug("no certs appended, using system certs only")
+		}
+		t.TLSClientConfig.RootCAs = rootCAs
+	}
+
+	cert, err := server.LoadClientCertificate()
+	if err != nil {
+		return nil, err
+	}
+	if cert != nil {
+		t.TLSClientConfig.Certificates = []tls.Certificate{*cert}
+	}
+
+	t.TLSClientConfig.InsecureSkipVerify = server.InsecureSkipTLSVerify
+	t.TLSClientConfig.ServerName = server.TLSServerName
+
+	if server.PinnedPublicKey != "" {
+		// we verify the certificate ourselves, so a changed key is reported clearly rather than as
+		// an unknown certificate authority
+		host, err := verificationHost(server)
+		if err != nil {
+			return nil, err
+		}
+		t.TLSClientConfig.VerifyConnection = verifyPinnedConnection(server.PinnedPublicKey, host, t.TLSClientConfig.RootCAs, server.InsecureSkipTLSVerify)
+		t.TLSClientConfig.InsecureSkipVerify = true
+	}
+
+	if server.ProxyURL != "" || server.NoProxy != "" {
+		proxy := httpproxy.FromEnvironment()
+		if server.ProxyURL != "" {
+			if _, err := url.Parse(server.ProxyURL); err != nil {
+				return nil, fmt.Errorf("invalid proxy url %q: %w", server.ProxyURL, err)
+			}
+			proxy.HTTPProxy = server.ProxyURL
+			proxy.HTTPSProxy = server.ProxyURL
+		}
+		if server.NoProxy != "" {
+			proxy.NoProxy = server.NoProxy
+		}
+		proxyFunc := proxy.ProxyFunc()
+		t.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxyFunc(req.URL)
+		}
+	}
+
+	return t, nil
+}
+
+// verifyPinnedConnection checks the server presents the pinned public key, then verifies its
+// certificate as the standard verification would
+func verifyPinnedConnection(pin, host string, roots *x509.CertPool, insecure bool) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return fmt.Errorf("server %s did not present a certificate", host)
+		}
+		leaf := cs.PeerCertificates[0]
+		if presented := config.PublicKeyPin(leaf); presented != pin {
+			return &ErrServerKeyChanged{Server: host, Pinned: pin, Presented: presented}
+		}
+		if insecure {
+			return nil
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range cs.PeerCertificates[1:] {
+			intermediates.AddCert(cert)
+		}
+		// the server name is empty for an IP endpoint, so verify against the host, which may be
+		// an IP address
+		_, err := leaf.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			DNSName:       host,
+			Intermediates: intermediates,
+		})
+
+		return err
+	}
+}
@@ -0,0 +1,376 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/appvia/wfclient/pkg/client/config"
+)
+
+// newTestClientCertificate creates a CA and a client certificate signed by it, returning the CA
+// pool and the PEM-encoded certificate and key
+func newTestClientCertificate(t *testing.T) (*x509.CertPool, string, string) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "test-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	return pool,
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func newTestMTLSServer(t *testing.T, clientCAs *x509.CertPool) (*httptest.Server, string) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name":"` + r.TLS.PeerCertificates[0].Subject.CommonName + `"}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	serverCA := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	return server, serverCA
+}
+
+func newTestServerClient(t *testing.T, server *config.Server) Interface {
+	cfg := config.NewEmpty()
+	cfg.CreateProfile("test", server.Endpoint)
+	cfg.AddServer("test", server)
+	cfg.AddAuthInfo("test", &config.AuthInfo{Token: new(string)})
+	cfg.CurrentProfile = "test"
+
+	c, err := New(cfg)
+	require.NoError(t, err)
+
+	return c
+}
+
+func TestMutualTLS(t *testing.T) {
+	pool, cert, key := newTestClientCertificate(t)
+	server, serverCA := newTestMTLSServer(t, pool)
+
+	// inline certificates
+	result := map[string]string{}
+	c := newTestServerClient(t, &config.Server{Endpoint: server.URL, CACertificate: serverCA, ClientCertificate: cert, ClientKey: key})
+	require.NoError(t, c.Request().RawEndpoint("/").Result(&result).Get().Error())
+	assert.Equal(t, "test-client", result["name"])
+
+	// certificates from files
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tls.crt"), []byte(cert), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tls.key"), []byte(key), 0600))
+	c = newTestServerClient(t, &config.Server{
+		Endpoint:          server.URL,
+		CACertificate:     serverCA,
+		ClientCertificate: filepath.Join(dir, "tls.crt"),
+		ClientKey:         filepath.Join(dir, "tls.key"),
+	})
+	require.NoError(t, c.Request().RawEndpoint("/").Get().Error())
+
+	// no client certificate
+	c = newTestServerClient(t, &config.Server{Endpoint: server.URL, CACertificate: serverCA})
+	require.Error(t, c.Request().RawEndpoint("/").Get().Error())
+}
+
+func TestTLSServerName(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+	serverCA := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	// the test server certificate is valid for example.com
+	c := newTestServerClient(t, &config.Server{Endpoint: server.URL, CACertificate: serverCA, TLSServerName: "example.com"})
+	require.NoError(t, c.Request().RawEndpoint("/").Get().Error())
+
+	c = newTestServerClient(t, &config.Server{Endpoint: server.URL, CACertificate: serverCA, TLSServerName: "wayfinder.example.org"})
+	require.Error(t, c.Request().RawEndpoint("/").Get().Error())
+}
+
+func TestHTTPClientCached(t *testing.T) {
+	clients := newHTTPClientCache(nil)
+	server := &config.Server{Endpoint: "https://wayfinder.example.com", InsecureSkipTLSVerify: true, NoProxy: "example.com"}
+
+	first, err := clients.clientFor("test", server, false)
+	require.NoError(t, err)
+	second, err := clients.clientFor("other", &config.Server{Endpoint: "https://other.example.com", InsecureSkipTLSVerify: true, NoProxy: "example.com"}, false)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	stream, err := clients.clientFor("test", server, true)
+	require.NoError(t, err)
+	assert.NotSame(t, first, stream)
+	assert.Same(t, first.Transport, stream.Transport)
+	assert.Zero(t, stream.Timeout)
+	assert.NotZero(t, first.Timeout)
+
+	third, err := clients.clientFor("third", &config.Server{Endpoint: "https://third.example.com"}, false)
+	require.NoError(t, err)
+	assert.NotSame(t, first, third)
+}
+
+func TestHTTPClientCacheInvalidated(t *testing.T) {
+	clients := newHTTPClientCache(nil)
+	server := &config.Server{Endpoint: "https://wayfinder.example.com", InsecureSkipTLSVerify: true}
+
+	first, err := clients.clientFor("test", server, false)
+	require.NoError(t, err)
+
+	// the settings of the server change, so the client is rebuilt and the old one dropped
+	server.InsecureSkipTLSVerify = false
+	server.TLSServerName = "example.com"
+	second, err := clients.clientFor("test", server, false)
+	require.NoError(t, err)
+	assert.NotSame(t, first, second)
+	assert.Len(t, clients.entries, 1)
+
+	// a client still used by another server is kept
+	_, err = clients.clientFor("other", &config.Server{Endpoint: "https://other.example.com", TLSServerName: "example.com"}, false)
+	require.NoError(t, err)
+	server.TLSServerName = ""
+	_, err = clients.clientFor("test", server, false)
+	require.NoError(t, err)
+	assert.Len(t, clients.entries, 2)
+
+	// servers removed from the configuration are dropped
+	clients.retain(map[string]*config.Server{"test": server})
+	assert.Len(t, clients.entries, 1)
+
+	clients.close()
+	assert.Empty(t, clients.entries)
+	assert.Empty(t, clients.servers)
+}
+
+func TestHTTPClientCachePinnedHost(t *testing.T) {
+	clients := newHTTPClientCache(nil)
+	pin := "sha256/AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="
+
+	// the pinned transports verify against their own endpoint, so are not shared
+	first, err := clients.clientFor("a", &config.Server{Endpoint: "https://a.example.com", PinnedPublicKey: pin}, false)
+	require.NoError(t, err)
+	second, err := clients.clientFor("b", &config.Server{Endpoint: "https://b.example.com", PinnedPublicKey: pin}, false)
+	require.NoError(t, err)
+	assert.NotSame(t, first, second)
+
+	// unless they are verified against the same server name
+	third, err := clients.clientFor("c", &config.Server{Endpoint: "https://c.example.com", PinnedPublicKey: pin, TLSServerName: "a.example.com"}, false)
+	require.NoError(t, err)
+	fourth, err := clients.clientFor("d", &config.Server{Endpoint: "https://d.example.com", PinnedPublicKey: pin, TLSServerName: "a.example.com"}, false)
+	require.NoError(t, err)
+	assert.Same(t, third, fourth)
+}
+
+func TestHTTPClientCacheSameEndpoint(t *testing.T) {
+	clients := newHTTPClientCache(nil)
+	a := &config.Server{Endpoint: "https://wayfinder.example.com", TLSServerName: "a.example.com"}
+	b := &config.Server{Endpoint: "https://wayfinder.example.com", TLSServerName: "b.example.com"}
+
+	// servers sharing an endpoint with different settings keep their own clients
+	first, err := clients.clientFor("a", a, false)
+	require.NoError(t, err)
+	_, err = clients.clientFor("b", b, false)
+	require.NoError(t, err)
+	again, err := clients.clientFor("a", a, false)
+	require.NoError(t, err)
+	assert.Same(t, first, again)
+	assert.Len(t, clients.entries, 2)
+}
+
+func TestTransportProxy(t *testing.T) {
+	transport, err := buildTransport(&config.Server{ProxyURL: "http://proxy.example.com:3128", NoProxy: "internal.example.com"})
+	require.NoError(t, err)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://wayfinder.example.com/api", nil)
+	proxy, err := transport.Proxy(req)
+	require.NoError(t, err)
+	require.NotNil(t, proxy)
+	assert.Equal(t, "proxy.example.com:3128", proxy.Host)
+
+	req, _ = http.NewRequest(http.MethodGet, "https://wayfinder.internal.example.com/api", nil)
+	proxy, err = transport.Proxy(req)
+	require.NoError(t, err)
+	assert.Nil(t, proxy)
+}
+
+func TestPinnedPublicKey(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	cfg := config.NewEmpty()
+	require.NoError(t, cfg.CreateProfileWithTrust("test", server.URL, func(config.TrustInfo) (bool, error) {
+		return true, nil
+	}))
+	cfg.AddAuthInfo("test", &config.AuthInfo{Token: new(string)})
+	cfg.CurrentProfile = "test"
+
+	c, err := New(cfg)
+	require.NoError(t, err)
+	require.NoError(t, c.Request().RawEndpoint("/").Get().Error())
+
+	// the server now presents a different key to the one pinned
+	cfg.GetServer("test").PinnedPublicKey = "sha256/AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="
+	err = c.Request().RawEndpoint("/").Get().Error()
+	require.Error(t, err)
+	assert.True(t, IsServerKeyChanged(err))
+	assert.Contains(t, err.Error(), "has changed since it was first trusted")
+}
+
+func TestPinnedPublicKeyVerifiesHost(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	cfg := config.NewEmpty()
+	require.NoError(t, cfg.CreateProfileWithTrust("test", server.URL, func(config.TrustInfo) (bool, error) {
+		return true, nil
+	}))
+	cfg.AddAuthInfo("test", &config.AuthInfo{Token: new(string)})
+	cfg.CurrentProfile = "test"
+
+	c, err := New(cfg)
+	require.NoError(t, err)
+	// the endpoint is an IP address, which the certificate must be valid for
+	require.NoError(t, c.Request().RawEndpoint("/").Get().Error())
+
+	// the pinned key still matches, but the certificate is not valid for the server name
+	cfg.GetServer("test").TLSServerName = "wayfinder.test"
+	err = c.Request().RawEndpoint("/").Get().Error()
+	require.Error(t, err)
+	assert.False(t, IsServerKeyChanged(err))
+	assert.Contains(t, err.Error(), "wayfinder.test")
+}
+
+// newTestHandshakeServer starts a TLS server counting the handshakes made with it, returning a
+// client configuration trusting it
+func newTestHandshakeServer(tb testing.TB) (*config.Config, *int64) {
+	handshakes := new(int64)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	}))
+	server.TLS = &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			atomic.AddInt64(handshakes, 1)
+			return nil, nil
+		},
+	}
+	server.StartTLS()
+	tb.Cleanup(server.Close)
+
+	cfg := config.NewEmpty()
+	cfg.CreateProfile("test", server.URL)
+	cfg.AddServer("test", &config.Server{
+		Endpoint:      server.URL,
+		CACertificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})),
+	})
+	cfg.AddAuthInfo("test", &config.AuthInfo{Token: new(string)})
+	cfg.CurrentProfile = "test"
+
+	return cfg, handshakes
+}
+
+func TestHTTPClientReusesConnections(t *testing.T) {
+	cfg, handshakes := newTestHandshakeServer(t)
+	c, err := New(cfg)
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, c.Request().RawEndpoint("/").Get().Error())
+	}
+	assert.Equal(t, int64(1), atomic.LoadInt64(handshakes))
+}
+
+// benchmarkRequests reports the handshakes made over each loop of 1000 requests, made through one
+// client per loop or a new client per request
+func benchmarkRequests(b *testing.B, clientPerRequest bool) {
+	cfg, handshakes := newTestHandshakeServer(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		c := NewClient(cfg)
+		for j := 0; j < 1000; j++ {
+			if clientPerRequest {
+				c = NewClient(cfg)
+			}
+			if err := c.Request().RawEndpoint("/").Get().Error(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.ReportMetric(float64(atomic.LoadInt64(handshakes))/float64(b.N), "handshakes/op")
+}
+
+// BenchmarkRequestsCachedClient makes the requests through one client, reusing its connections
+func BenchmarkRequestsCachedClient(b *testing.B) {
+	benchmarkRequests(b, false)
+}
+
+// BenchmarkRequestsUncachedClient makes each request through a new client, as a baseline where
+// nothing is reused
+func BenchmarkRequestsUncachedClient(b *testing.B) {
+	benchmarkRequests(b, true)
+}
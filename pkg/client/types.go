@@ -124,6 +124,25 @@ type Interface interface {
 	CheckServer(force, saveProfile bool) error
 }
 
+// SessionClient is an Interface which can also log in and out of the server of the current
+// profile. It is implemented by the clients returned by New, and is kept apart from Interface so
+// other implementations of Interface need not provide it.
+type SessionClient interface {
+	Interface
+	// LoginLocal logs in as a local user, storing the issued tokens in the current profile
+	LoginLocal(ctx context.Context, username, password string) error
+	// LoginWithAccessToken logs in with an access token, storing it in the current profile
+	LoginWithAccessToken(ctx context.Context, token string) error
+	// LoginSSO logs in through the single sign-on provider of the server, storing the issued
+	// tokens in the current profile
+	LoginSSO(ctx context.Context, options SSOLoginOptions) error
+	// Logout revokes and clears the credentials of the current profile
+	Logout(ctx context.Context) error
+	// Close closes idle connections and finishes any HTTP archive being recorded. The client
+	// should not be used once closed.
+	Close() error
+}
+
 // UpdateHandlerFunc is external method when the configuration has been updated
 type UpdateHandlerFunc func() error
 
@@ -238,6 +257,11 @@ type APIError struct {
 	URI string `json:"uri"`
 	// Verb was the http request verb used
 	Verb string `json:"verb"`
+	// Reason is a machine readable description of the error, as provided by the server or
+	// otherwise derived from the status code
+	Reason metav1.StatusReason `json:"reason,omitempty"`
+	// Causes are the detailed causes of the error, as provided by the server
+	Causes []metav1.StatusCause `json:"causes,omitempty"`
 	// Validation will be populated with the underlying structured validation error if applicable
 	Validation *validation.Error
 	// DependencyViolation will be populated with the underlying structured dependency violation
@@ -250,11 +274,41 @@ func (e APIError) Error() string {
 	return e.Message
 }
 
-// Is reports whether the error message matches the target error message
+// Is reports whether the target is an API error with the same code and, where the target has
+// one, the same reason
 func (e APIError) Is(target error) bool {
-	return e.Message == target.Error()
+	var t APIError
+	switch v := target.(type) {
+	case APIError:
+		t = v
+	case *APIError:
+		if v == nil {
+			return false
+		}
+		t = *v
+	default:
+		return false
+	}
+
+	return e.Code == t.Code && (t.Reason == "" || e.Reason == t.Reason)
+}
+
+// Unwrap returns the underlying structured validation or dependency violation errors, if any, so
+// they can be retrieved with errors.As
+func (e APIError) Unwrap() []error {
+	var errs []error
+	if e.Validation != nil {
+		errs = append(errs, e.Validation)
+	}
+	if e.DependencyViolation != nil {
+		errs = append(errs, *e.DependencyViolation)
+	}
+	return errs
 }
 
 type WarningHandler func(context.Context, []validation.Warning)
 
+// DeletionStatusHandler is called with the current status of an object being deleted
+type DeletionStatusHandler func(context.Context, Object, corev1.CommonStatus)
+
 type RequestDo func(req *http.Request) (*http.Response, error)
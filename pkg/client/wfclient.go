@@ -152,7 +152,13 @@ func (s *wfClient) Delete(ctx context.Context, obj Object, opts ...DeleteOption)
 	if o.Force {
 		req = req.Parameters(ForceParameter())
 	}
-	return req.Delete().Error()
+	if err := req.Delete().Error(); err != nil {
+		return err
+	}
+	if o.WaitForDeletion > 0 && !o.DryRun {
+		return s.waitForDeletion(ctx, obj, o)
+	}
+	return nil
 }
 
 func (s *wfClient) DeleteAllVersions(ctx context.Context, key ObjectKey, list ObjectList, opts ...DeleteOption) error {
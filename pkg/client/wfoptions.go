@@ -17,6 +17,8 @@
 package client
 
 import (
+	"time"
+
 	corev1 "github.com/appvia/wfclient/pkg/apis/core/v1alpha1"
 )
 
@@ -30,6 +32,12 @@ type DeleteOptions struct {
 	Orphan  bool
 	Cascade bool
 	Force   bool
+	// WaitForDeletion is how long to wait for the object to be removed after the delete has been
+	// accepted - zero means do not wait
+	WaitForDeletion time.Duration
+	// DeletionStatusHandler is called with the status of the object whenever it changes while
+	// waiting for deletion
+	DeletionStatusHandler DeletionStatusHandler
 }
 
 type CreateOptions struct {
@@ -127,6 +135,22 @@ func (n WithDryRun) ApplyToCreate(opts *CreateOptions) {
 	opts.DryRun = bool(n)
 }
 
+// WithWaitForDeletion waits up to the specified duration for a deleted object to be removed from
+// Wayfinder, rather than returning as soon as the delete is accepted.
+type WithWaitForDeletion time.Duration
+
+func (n WithWaitForDeletion) ApplyToDelete(opts *DeleteOptions) {
+	opts.WaitForDeletion = time.Duration(n)
+}
+
+// WithDeletionStatusHandler is called with the status of the object each time it changes while
+// waiting for deletion. Use with WithWaitForDeletion.
+type WithDeletionStatusHandler DeletionStatusHandler
+
+func (n WithDeletionStatusHandler) ApplyToDelete(opts *DeleteOptions) {
+	opts.DeletionStatusHandler = DeletionStatusHandler(n)
+}
+
 type WithForce bool
 
 func (n WithForce) ApplyToDelete(opts *DeleteOptions) {
@@ -50,17 +50,30 @@ func WithUser(ctx context.Context, user string) context.Context {
 	return context.WithValue(ctx, logContextUser, user)
 }
 
+// UserFromContext returns the username added to the context by WithUser, if any
+func UserFromContext(ctx context.Context) (string, bool) {
+	user, ok := ctx.Value(logContextUser).(string)
+
+	return user, ok
+}
+
 // LogWithoutContext should ONLY be used where there is no relevant context - you should prefer
 // calling Log(ctx) and add contexts in where they are missing to using this.
 func LogWithoutContext() Logger {
 	return logProvider()
 }
 
-// Log gets a logger to use, initialised with any relevant values from the context
+// Log gets a logger to use, initialised with any relevant values from the context. The context is
+// attached to the entries logged, so handlers such as slog can use it.
 func Log(ctx context.Context) Logger {
 	log := logProvider()
 	if ctx != nil {
-		if user, ok := ctx.Value(logContextUser).(string); ok {
+		if l, ok := log.(interface {
+			WithContext(context.Context) *logrus.Entry
+		}); ok {
+			log = l.WithContext(ctx)
+		}
+		if user, ok := UserFromContext(ctx); ok {
 			log = log.WithField("user", user)
 		}
 	}
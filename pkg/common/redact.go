@@ -0,0 +1,254 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces sensitive values in logged payloads and headers
+const Redacted = "[redacted]"
+
+// redactedKeys are the fields whose values are always redacted, wherever they appear. Keys are
+// matched exactly, as some sensitive fields are distinguished from others only by case.
+var redactedKeys = map[string]bool{
+	// AppDeploymentRequest.Secrets has no json tag so is encoded with a capital
+	"Secrets":       true,
+	"token":         true,
+	"refresh-token": true,
+	"refreshToken":  true,
+	"password":      true,
+	"clientKey":     true,
+	"codeVerifier":  true,
+	"Authorization": true,
+	// AppDeploymentRequest.Vars has no json tag, and carries no indication of which are sensitive
+	"Vars": true,
+}
+
+// redactedValueLists are the fields holding lists of name and value pairs, such as the vars and
+// input values submitted for an application or component. The submitted values carry no flag to
+// say which are sensitive, so every value is redacted while the names are kept.
+var redactedValueLists = map[string]bool{
+	"vars":   true,
+	"values": true,
+	"inputs": true,
+}
+
+// sensitiveValueKeys are the fields holding the value of a sensitive variable, input or output
+var sensitiveValueKeys = []string{"value", "default"}
+
+// redactedHeaders are the headers whose values are redacted
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// jwtPattern matches anything which looks like a JWT, redacted wherever it appears
+var jwtPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+
+// redactedPaths are additional dot-separated paths to redact, registered by users
+var redactedPaths = struct {
+	sync.RWMutex
+	paths [][]string
+}{}
+
+// RegisterRedactedPaths adds dot-separated JSON paths whose values are redacted from logged
+// payloads, such as "spec.credentials.password". A path segment of * matches any field, and
+// arrays are traversed transparently, so "items.token" matches the token of every item.
+func RegisterRedactedPaths(paths ...string) {
+	redactedPaths.Lock()
+	defer redactedPaths.Unlock()
+
+	for _, p := range paths {
+		if p != "" {
+			redactedPaths.paths = append(redactedPaths.paths, strings.Split(p, "."))
+		}
+	}
+}
+
+// RedactJSON returns the JSON document with sensitive values redacted, for logging. A document
+// which cannot be decoded is returned with anything resembling a token redacted.
+func RedactJSON(data []byte) string {
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return RedactText(string(data))
+	}
+
+	redactedPaths.RLock()
+	paths := redactedPaths.paths
+	redactedPaths.RUnlock()
+
+	encoded, err := json.Marshal(redactValue(doc, nil, paths))
+	if err != nil {
+		return Redacted
+	}
+
+	return string(encoded)
+}
+
+// RedactedJSON is a JSON document which is redacted when formatted, so the cost of redaction is
+// only paid when the log entry is written
+type RedactedJSON []byte
+
+// String returns the redacted document
+func (r RedactedJSON) String() string {
+	return RedactJSON(r)
+}
+
+// MarshalJSON encodes the redacted document as a string, for structured log formatters
+func (r RedactedJSON) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+// RedactBody returns a request or response body with secrets redacted. Streamed bodies may hold
+// several documents, so only a single JSON document is redacted as JSON.
+func RedactBody(body []byte) string {
+	if json.Valid(body) {
+		return RedactJSON(body)
+	}
+
+	return RedactText(string(body))
+}
+
+// RedactText returns the text with anything resembling a token redacted
+func RedactText(text string) string {
+	return jwtPattern.ReplaceAllString(text, Redacted)
+}
+
+// RedactHeaders returns a copy of the headers with credentials redacted
+func RedactHeaders(headers http.Header) http.Header {
+	redacted := headers.Clone()
+	for _, name := range redactedHeaders {
+		if len(redacted.Values(name)) > 0 {
+			redacted.Set(name, Redacted)
+		}
+	}
+
+	return redacted
+}
+
+// redactValue redacts a decoded JSON value found at the path
+func redactValue(value interface{}, path []string, paths [][]string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		sensitive, _ := v["sensitive"].(bool)
+		for key, child := range v {
+			childPath := append(path[:len(path):len(path)], key)
+			switch {
+			case redactedKeys[key], matchesPath(childPath, paths):
+				v[key] = redactAll(child)
+			case sensitive && isSensitiveValueKey(key):
+				v[key] = redactAll(child)
+			case redactedValueLists[key]:
+				v[key] = redactNamedValues(child, childPath, paths)
+			default:
+				v[key] = redactValue(child, childPath, paths)
+			}
+		}
+		return v
+
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValue(v[i], path, paths)
+		}
+		return v
+
+	case string:
+		return RedactText(v)
+	}
+
+	return value
+}
+
+// redactNamedValues redacts the value of each name and value pair in a list, leaving anything else
+// to be redacted as normal
+func redactNamedValues(value interface{}, path []string, paths [][]string) interface{} {
+	list, ok := value.([]interface{})
+	if !ok {
+		return redactValue(value, path, paths)
+	}
+	for i, item := range list {
+		pair, ok := item.(map[string]interface{})
+		if !ok {
+			list[i] = redactValue(item, path, paths)
+			continue
+		}
+		_, named := pair["name"]
+		if v, found := pair["value"]; found && named {
+			pair["value"] = redactAll(v)
+		}
+		list[i] = redactValue(pair, path, paths)
+	}
+
+	return list
+}
+
+// redactAll redacts every value beneath a sensitive field, keeping the structure and keys so it
+// is still clear what was sent
+func redactAll(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = redactAll(child)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = redactAll(v[i])
+		}
+		return v
+	case nil:
+		return nil
+	}
+
+	return Redacted
+}
+
+func isSensitiveValueKey(key string) bool {
+	for _, k := range sensitiveValueKeys {
+		if k == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesPath checks if the path matches any of the registered paths
+func matchesPath(path []string, paths [][]string) bool {
+	for _, p := range paths {
+		if len(p) != len(path) {
+			continue
+		}
+		matched := true
+		for i := range p {
+			if p[i] != "*" && p[i] != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
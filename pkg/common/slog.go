@@ -0,0 +1,111 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// LevelTrace is the slog level trace messages are logged at
+	LevelTrace = slog.LevelDebug - 4
+	// LevelFatal is the slog level fatal messages are logged at, before exiting
+	LevelFatal = slog.LevelError + 4
+	// LevelPanic is the slog level panic messages are logged at, before panicking
+	LevelPanic = slog.LevelError + 8
+)
+
+// slogLevels maps logrus levels onto slog levels
+var slogLevels = map[logrus.Level]slog.Level{
+	logrus.TraceLevel: LevelTrace,
+	logrus.DebugLevel: slog.LevelDebug,
+	logrus.InfoLevel:  slog.LevelInfo,
+	logrus.WarnLevel:  slog.LevelWarn,
+	logrus.ErrorLevel: slog.LevelError,
+	logrus.FatalLevel: LevelFatal,
+	logrus.PanicLevel: LevelPanic,
+}
+
+// SetSlogLogger sends all logging to the slog logger
+func SetSlogLogger(l *slog.Logger) {
+	logger := NewSlogLogger(l)
+	SetLogProvider(func() Logger {
+		return logger
+	})
+}
+
+// NewSlogLogger returns a Logger which writes to the slog logger. Fields are mapped to attributes,
+// and the context passed to Log is passed on to the slog handler.
+func NewSlogLogger(l *slog.Logger) Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.SetFormatter(discardFormatter{})
+	logger.AddHook(&slogHook{handler: l.Handler()})
+
+	// @step: the handler level may change at any time, e.g. with a slog.LevelVar, so every entry is
+	// passed to the hook to be filtered by the handler
+	logger.SetLevel(logrus.TraceLevel)
+
+	return logger
+}
+
+// slogHook forwards logrus entries to a slog handler
+type slogHook struct {
+	handler slog.Handler
+}
+
+// Levels returns the levels the hook is fired for
+func (h *slogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire writes the entry to the slog handler
+func (h *slogHook) Fire(entry *logrus.Entry) error {
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	level := slogLevels[entry.Level]
+	if !h.handler.Enabled(ctx, level) {
+		return nil
+	}
+
+	record := slog.NewRecord(entry.Time, level, entry.Message, 0)
+	keys := make([]string, 0, len(entry.Data))
+	for key := range entry.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		record.AddAttrs(slog.Any(key, entry.Data[key]))
+	}
+
+	return h.handler.Handle(ctx, record)
+}
+
+// discardFormatter skips formatting entries, as they are written by the hook instead
+type discardFormatter struct{}
+
+// Format returns nothing
+func (discardFormatter) Format(*logrus.Entry) ([]byte, error) {
+	return nil, nil
+}
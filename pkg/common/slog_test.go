@@ -0,0 +1,143 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useSlogBuffer sends logging to a JSON slog handler at the level, returning the output
+func useSlogBuffer(t *testing.T, level slog.Level) *bytes.Buffer {
+	out := &bytes.Buffer{}
+	SetSlogLogger(slog.New(slog.NewJSONHandler(out, &slog.HandlerOptions{Level: level})))
+	t.Cleanup(func() {
+		SetLogProvider(func() Logger { return logrus.StandardLogger() })
+	})
+
+	return out
+}
+
+func decodeSlogLines(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		decoded := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(line), &decoded))
+		lines = append(lines, decoded)
+	}
+
+	return lines
+}
+
+func TestSlogLoggerFieldsAndLevels(t *testing.T) {
+	out := useSlogBuffer(t, LevelTrace)
+
+	LogWithoutContext().WithFields(map[string]interface{}{"endpoint": "https://wf", "code": 200}).Debug("API request")
+	LogWithoutContext().WithError(errors.New("boom")).Warn("failed")
+	LogWithoutContext().WithField("payload", RedactedJSON(`{"token":"secret"}`)).Trace("payload")
+
+	lines := decodeSlogLines(t, out)
+	require.Len(t, lines, 3)
+
+	assert.Equal(t, "DEBUG", lines[0]["level"])
+	assert.Equal(t, "API request", lines[0]["msg"])
+	assert.Equal(t, "https://wf", lines[0]["endpoint"])
+	assert.Equal(t, 200.0, lines[0]["code"])
+
+	assert.Equal(t, "WARN", lines[1]["level"])
+	assert.Equal(t, "boom", lines[1]["error"])
+
+	assert.Equal(t, "DEBUG-4", lines[2]["level"])
+	assert.Equal(t, `{"token":"[redacted]"}`, lines[2]["payload"])
+}
+
+func TestSlogLoggerLevelFiltering(t *testing.T) {
+	out := useSlogBuffer(t, slog.LevelInfo)
+
+	LogWithoutContext().Trace("hidden")
+	LogWithoutContext().Debug("hidden")
+	LogWithoutContext().Info("shown")
+
+	lines := decodeSlogLines(t, out)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "shown", lines[0]["msg"])
+}
+
+func TestSlogLoggerLevelVar(t *testing.T) {
+	out := &bytes.Buffer{}
+	level := &slog.LevelVar{}
+	level.Set(slog.LevelInfo)
+	SetSlogLogger(slog.New(slog.NewJSONHandler(out, &slog.HandlerOptions{Level: level})))
+	t.Cleanup(func() {
+		SetLogProvider(func() Logger { return logrus.StandardLogger() })
+	})
+
+	LogWithoutContext().Debug("hidden")
+	// lowering the level of the handler enables debug logging without a new logger
+	level.Set(slog.LevelDebug)
+	LogWithoutContext().Debug("shown")
+	LogWithoutContext().Trace("hidden")
+
+	lines := decodeSlogLines(t, out)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "shown", lines[0]["msg"])
+}
+
+type requestIDKey struct{}
+
+// contextHandler adds the request id from the context to each record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		r.AddAttrs(slog.String("requestID", id))
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func TestSlogLoggerContext(t *testing.T) {
+	out := &bytes.Buffer{}
+	SetSlogLogger(slog.New(contextHandler{slog.NewJSONHandler(out, nil)}))
+	defer SetLogProvider(func() Logger { return logrus.StandardLogger() })
+
+	ctx := WithUser(context.WithValue(context.Background(), requestIDKey{}, "req-1"), "jane")
+	Log(ctx).Info("hello")
+
+	lines := decodeSlogLines(t, out)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "jane", lines[0]["user"])
+	assert.Equal(t, "req-1", lines[0]["requestID"])
+
+	user, found := UserFromContext(ctx)
+	assert.True(t, found)
+	assert.Equal(t, "jane", user)
+}
@@ -0,0 +1,62 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httputils
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// CaptureBody wraps a response body so its content is captured as it is read, calling done with
+// the content once the body has been read to the end or closed. This lets streamed responses be
+// recorded without buffering them up front.
+func CaptureBody(body io.ReadCloser, done func([]byte)) io.ReadCloser {
+	return &capturingBody{ReadCloser: body, done: done}
+}
+
+// capturingBody captures the body as it is read
+type capturingBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	once sync.Once
+	done func([]byte)
+}
+
+// Read implements io.Reader
+func (b *capturingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	if err == io.EOF {
+		b.finish()
+	}
+
+	return n, err
+}
+
+// Close implements io.Closer
+func (b *capturingBody) Close() error {
+	b.finish()
+
+	return b.ReadCloser.Close()
+}
+
+func (b *capturingBody) finish() {
+	b.once.Do(func() {
+		b.done(b.buf.Bytes())
+	})
+}
@@ -259,3 +259,14 @@ func (c *Claims) JSON() ([]byte, error) {
 
 	return b.Bytes(), nil
 }
+
+// getTime returns a numeric date claim as a time
+func (c *Claims) getTime(key string) (time.Time, bool) {
+	value, found := c.GetFloat64(key)
+	if !found {
+		return time.Time{}, false
+	}
+
+	sec, dec := math.Modf(value)
+	return time.Unix(int64(sec), int64(dec*(1e9))), true
+}
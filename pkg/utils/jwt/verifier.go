@@ -0,0 +1,393 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// DefaultLeeway is the clock skew allowed when checking the expiry and not before times
+	DefaultLeeway = time.Minute
+	// DefaultKeySetRefresh is how long a fetched key set is cached for
+	DefaultKeySetRefresh = time.Hour
+	// minKeySetRefresh limits how often a key set is fetched when a token references an unknown key
+	minKeySetRefresh = time.Minute
+)
+
+var (
+	// ErrTokenExpired indicates the token has expired
+	ErrTokenExpired = errors.New("token has expired")
+	// ErrTokenNotYetValid indicates the token is not valid yet
+	ErrTokenNotYetValid = errors.New("token is not valid yet")
+	// ErrInvalidAudience indicates the token is not intended for any of the accepted audiences
+	ErrInvalidAudience = errors.New("token audience is not accepted")
+	// ErrInvalidIssuer indicates the token was not issued by the expected issuer
+	ErrInvalidIssuer = errors.New("token issuer is not accepted")
+	// ErrUnknownKey indicates the token was signed by a key which is not in the key set
+	ErrUnknownKey = errors.New("token signing key is not known")
+)
+
+// VerifierOptions configure a Verifier
+type VerifierOptions struct {
+	// JWKSURL is the url of the JSON web key set of the issuer, fetched and cached as required
+	JWKSURL string
+	// KeySet is a static JSON web key set, used instead of fetching one from JWKSURL
+	KeySet []byte
+	// Issuer is the expected issuer of tokens, when set
+	Issuer string
+	// Audiences are the accepted audiences, a token must be intended for at least one of them
+	Audiences []string
+	// Leeway is the clock skew allowed when checking times, defaulting to DefaultLeeway
+	Leeway time.Duration
+	// RefreshInterval is how long a fetched key set is cached for, defaulting to
+	// DefaultKeySetRefresh
+	RefreshInterval time.Duration
+	// HTTPClient is used to fetch the key set, defaulting to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// Verifier verifies the signatures and claims of tokens against a JSON web key set
+type Verifier struct {
+	options VerifierOptions
+	// now returns the current time, overridden in tests
+	now func() time.Time
+
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+// VerifiedClaims are the claims of a token whose signature and claims have been verified
+type VerifiedClaims struct {
+	*Claims
+	// KeyID is the id of the key the token was signed with
+	KeyID string
+	// Subject is the subject of the token
+	Subject string
+	// Issuer is the issuer of the token
+	Issuer string
+	// Audience is the audience of the token
+	Audience []string
+	// Scopes are the scopes granted to the token
+	Scopes []string
+	// IssuedAt is when the token was issued, if known
+	IssuedAt time.Time
+	// NotBefore is when the token becomes valid, if set
+	NotBefore time.Time
+	// Expiry is when the token expires
+	Expiry time.Time
+}
+
+// HasScope checks if the token was granted the scope
+func (v *VerifiedClaims) HasScope(scope string) bool {
+	for _, s := range v.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// jsonWebKey is a single key of a JSON web key set, as described in RFC 7517
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// NewVerifier returns a verifier for the options
+func NewVerifier(options VerifierOptions) (*Verifier, error) {
+	if options.JWKSURL == "" && len(options.KeySet) == 0 {
+		return nil, errors.New("either a key set or the url of one is required")
+	}
+	if len(options.Audiences) == 0 {
+		return nil, errors.New("at least one accepted audience is required")
+	}
+	if options.Leeway == 0 {
+		options.Leeway = DefaultLeeway
+	}
+	if options.RefreshInterval == 0 {
+		options.RefreshInterval = DefaultKeySetRefresh
+	}
+	if options.HTTPClient == nil {
+		options.HTTPClient = http.DefaultClient
+	}
+
+	v := &Verifier{options: options, now: time.Now}
+	if len(options.KeySet) > 0 {
+		keys, err := ParseKeySet(options.KeySet)
+		if err != nil {
+			return nil, err
+		}
+		v.keys = keys
+	}
+
+	return v, nil
+}
+
+// Verify checks the signature of the token is from a key in the key set, and that its issuer,
+// audience, expiry and not before claims are valid, returning the verified claims
+func (v *Verifier) Verify(ctx context.Context, raw string) (*VerifiedClaims, error) {
+	parser := &jwt.Parser{
+		ValidMethods:         []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()},
+		SkipClaimsValidation: true,
+	}
+
+	var kid string
+	token, err := parser.ParseWithClaims(raw, jwt.MapClaims{}, func(token *jwt.Token) (interface{}, error) {
+		kid, _ = token.Header["kid"].(string)
+
+		return v.key(ctx, kid)
+	})
+	if err != nil {
+		var verr *jwt.ValidationError
+		if errors.As(err, &verr) && verr.Inner != nil {
+			return nil, verr.Inner
+		}
+		return nil, err
+	}
+
+	claims := &VerifiedClaims{Claims: NewClaims(token.Claims.(jwt.MapClaims)), KeyID: kid}
+	if err := v.verifyClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// verifyClaims checks the registered claims of the token, populating the verified claims
+func (v *Verifier) verifyClaims(claims *VerifiedClaims) error {
+	now := v.now()
+
+	claims.Subject, _ = claims.GetSubject()
+	claims.Issuer, _ = claims.GetIssuer()
+	claims.Scopes, _ = claims.GetScopes()
+	if aud, found := claims.GetString("aud"); found {
+		claims.Audience = []string{aud}
+	} else {
+		claims.Audience, _ = claims.GetStringSlice("aud")
+	}
+	claims.IssuedAt, _ = claims.getTime("iat")
+	claims.NotBefore, _ = claims.getTime("nbf")
+
+	expiry, found := claims.GetExpiry()
+	if !found {
+		return errors.New("token does not have an expiry")
+	}
+	claims.Expiry = expiry
+
+	if now.After(claims.Expiry.Add(v.options.Leeway)) {
+		return ErrTokenExpired
+	}
+	if !claims.NotBefore.IsZero() && now.Add(v.options.Leeway).Before(claims.NotBefore) {
+		return ErrTokenNotYetValid
+	}
+	if v.options.Issuer != "" && claims.Issuer != v.options.Issuer {
+		return ErrInvalidIssuer
+	}
+
+	for _, aud := range claims.Audience {
+		for _, accepted := range v.options.Audiences {
+			if aud == accepted {
+				return nil
+			}
+		}
+	}
+
+	return ErrInvalidAudience
+}
+
+// key returns the public key with the id, fetching the key set if it is unknown
+func (v *Verifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if key, found := v.lookup(kid); found && !v.isStale() {
+		return key, nil
+	}
+	if v.options.JWKSURL == "" || (!v.fetched.IsZero() && v.now().Sub(v.fetched) < minKeySetRefresh) {
+		if key, found := v.lookup(kid); found {
+			return key, nil
+		}
+		return nil, ErrUnknownKey
+	}
+
+	keys, err := v.fetch(ctx)
+	if err != nil {
+		// @step: keep using the keys we have rather than failing every request
+		if key, found := v.lookup(kid); found {
+			return key, nil
+		}
+		return nil, err
+	}
+	v.keys, v.fetched = keys, v.now()
+
+	if key, found := v.lookup(kid); found {
+		return key, nil
+	}
+
+	return nil, ErrUnknownKey
+}
+
+// lookup finds the key with the id, or the only key when the token does not specify one
+func (v *Verifier) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, found := v.keys[kid]
+
+	return key, found
+}
+
+// isStale checks if a fetched key set should be refreshed
+func (v *Verifier) isStale() bool {
+	return v.options.JWKSURL != "" && v.now().Sub(v.fetched) > v.options.RefreshInterval
+}
+
+// fetch retrieves the key set from the url
+func (v *Verifier) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.options.JWKSURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.options.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch key set: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch key set: unexpected status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseKeySet(data)
+}
+
+// ParseKeySet parses the RSA and P-256 signing keys from a JSON web key set, keyed on their id
+func ParseKeySet(data []byte) (map[string]crypto.PublicKey, error) {
+	set := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid key set: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q in key set: %w", jwk.Kid, err)
+		}
+		if key != nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("key set does not contain any supported signing keys")
+	}
+
+	return keys, nil
+}
+
+// publicKey decodes the key, returning nil for unsupported key types
+func (k *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("exponent is too large")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, nil
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if len(x.Bytes()) > 32 || len(y.Bytes()) > 32 {
+			return nil, errors.New("invalid point")
+		}
+		point := append([]byte{4}, append(x.FillBytes(make([]byte, 32)), y.FillBytes(make([]byte, 32))...)...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	}
+
+	return nil, nil
+}
+
+// decodeBigInt decodes a base64url-encoded big-endian integer
+func decodeBigInt(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, errors.New("missing key parameter")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,236 @@
+/**
+ * Copyright 2025 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rsaJWK(kid string, key *rsa.PrivateKey) jsonWebKey {
+	return jsonWebKey{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) jsonWebKey {
+	return jsonWebKey{
+		Kty: "EC",
+		Kid: kid,
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func keySet(t *testing.T, keys ...jsonWebKey) []byte {
+	data, err := json.Marshal(map[string]interface{}{"keys": keys})
+	require.NoError(t, err)
+
+	return data
+}
+
+func signTestToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func testClaims(overrides jwt.MapClaims) jwt.MapClaims {
+	claims := jwt.MapClaims{
+		"iss":    "https://wayfinder.example.com",
+		"sub":    "admin",
+		"aud":    "wayfinder",
+		"scopes": []string{"wayfinder:system:user"},
+		"iat":    time.Now().Unix(),
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range overrides {
+		if v == nil {
+			delete(claims, k)
+		} else {
+			claims[k] = v
+		}
+	}
+
+	return claims
+}
+
+func TestVerifierRS256FromJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		_, _ = w.Write(keySet(t, rsaJWK("one", key)))
+	}))
+	defer server.Close()
+
+	v, err := NewVerifier(VerifierOptions{
+		JWKSURL:   server.URL,
+		Issuer:    "https://wayfinder.example.com",
+		Audiences: []string{"wayfinder", "kubernetes"},
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		claims, err := v.Verify(context.Background(), signTestToken(t, jwt.SigningMethodRS256, "one", key, testClaims(nil)))
+		require.NoError(t, err)
+		assert.Equal(t, "admin", claims.Subject)
+		assert.Equal(t, "one", claims.KeyID)
+		assert.Equal(t, []string{"wayfinder"}, claims.Audience)
+		assert.True(t, claims.HasScope("wayfinder:system:user"))
+		assert.False(t, claims.IssuedAt.IsZero())
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+}
+
+func TestVerifierES256FromKeySet(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	v, err := NewVerifier(VerifierOptions{KeySet: keySet(t, ecJWK("ec", key)), Audiences: []string{"refresh"}})
+	require.NoError(t, err)
+
+	claims, err := v.Verify(context.Background(), signTestToken(t, jwt.SigningMethodES256, "", key, testClaims(jwt.MapClaims{
+		"aud": []string{"other", "refresh"},
+	})))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"other", "refresh"}, claims.Audience)
+}
+
+func TestVerifierRejects(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	v, err := NewVerifier(VerifierOptions{
+		KeySet:    keySet(t, rsaJWK("one", key)),
+		Issuer:    "https://wayfinder.example.com",
+		Audiences: []string{"wayfinder"},
+		Leeway:    30 * time.Second,
+	})
+	require.NoError(t, err)
+
+	cases := map[string]struct {
+		token string
+		err   error
+	}{
+		"expired": {
+			token: signTestToken(t, jwt.SigningMethodRS256, "one", key, testClaims(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()})),
+			err:   ErrTokenExpired,
+		},
+		"not yet valid": {
+			token: signTestToken(t, jwt.SigningMethodRS256, "one", key, testClaims(jwt.MapClaims{"nbf": time.Now().Add(time.Minute).Unix()})),
+			err:   ErrTokenNotYetValid,
+		},
+		"wrong audience": {
+			token: signTestToken(t, jwt.SigningMethodRS256, "one", key, testClaims(jwt.MapClaims{"aud": "kubernetes"})),
+			err:   ErrInvalidAudience,
+		},
+		"wrong issuer": {
+			token: signTestToken(t, jwt.SigningMethodRS256, "one", key, testClaims(jwt.MapClaims{"iss": "https://evil.example.com"})),
+			err:   ErrInvalidIssuer,
+		},
+		"unknown key": {
+			token: signTestToken(t, jwt.SigningMethodRS256, "two", key, testClaims(nil)),
+			err:   ErrUnknownKey,
+		},
+	}
+	for name, c := range cases {
+		_, err := v.Verify(context.Background(), c.token)
+		assert.Equal(t, c.err, err, name)
+	}
+
+	_, err = v.Verify(context.Background(), signTestToken(t, jwt.SigningMethodRS256, "one", other, testClaims(nil)))
+	assert.Error(t, err, "signed by another key")
+	_, err = v.Verify(context.Background(), signTestToken(t, jwt.SigningMethodHS256, "one", []byte("secret"), testClaims(nil)))
+	assert.Error(t, err, "symmetric algorithm")
+	_, err = v.Verify(context.Background(), signTestToken(t, jwt.SigningMethodRS256, "one", key, testClaims(jwt.MapClaims{"exp": nil})))
+	assert.Error(t, err, "no expiry")
+
+	_, err = v.Verify(context.Background(), signTestToken(t, jwt.SigningMethodRS256, "one", key, testClaims(jwt.MapClaims{"exp": time.Now().Add(-10 * time.Second).Unix()})))
+	assert.NoError(t, err, "expired within leeway")
+}
+
+func TestVerifierKeyRotation(t *testing.T) {
+	first, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	second, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var rotated atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if rotated.Load() {
+			_, _ = w.Write(keySet(t, rsaJWK("second", second)))
+			return
+		}
+		_, _ = w.Write(keySet(t, rsaJWK("first", first)))
+	}))
+	defer server.Close()
+
+	v, err := NewVerifier(VerifierOptions{JWKSURL: server.URL, Audiences: []string{"wayfinder"}})
+	require.NoError(t, err)
+	now := time.Now()
+	v.now = func() time.Time { return now }
+
+	_, err = v.Verify(context.Background(), signTestToken(t, jwt.SigningMethodRS256, "first", first, testClaims(nil)))
+	require.NoError(t, err)
+
+	rotated.Store(true)
+	token := signTestToken(t, jwt.SigningMethodRS256, "second", second, testClaims(nil))
+	_, err = v.Verify(context.Background(), token)
+	assert.Equal(t, ErrUnknownKey, err, "key set is not refetched immediately")
+
+	now = now.Add(2 * minKeySetRefresh)
+	_, err = v.Verify(context.Background(), token)
+	assert.NoError(t, err)
+}
+
+func TestParseKeySetInvalid(t *testing.T) {
+	_, err := ParseKeySet([]byte(`{"keys":[]}`))
+	assert.Error(t, err)
+
+	_, err = ParseKeySet([]byte(`{"keys":[{"kty":"EC","crv":"P-256","x":"AQ","y":"AQ"}]}`))
+	assert.Error(t, err)
+}